@@ -0,0 +1,46 @@
+//go:build tinygo
+
+// Package board describes how the controls and the display are wired on
+// the ESP32.
+//
+// The default build has one button on GPIO18. Build with -tags encoder for
+// a KY-040 rotary encoder, or -tags buttons for up/down/select buttons:
+//
+//	tinygo flash -target=esp32-coreboard -tags encoder tinygo_ssd1306_version.go
+//
+// Whichever is chosen, Navigator returns the same navigation events.
+//
+// The display defaults to a 128x64 SSD1306 on I2C. Tags pick another
+// module (sh1106, ssd1309 or oled128x32) and SPI instead of I2C (spi),
+// and combine with the control tags:
+//
+//	tinygo flash -target=esp32-coreboard -tags "sh1106 spi" tinygo_ssd1306_version.go
+//
+// Several panels can be tiled into one Game of Life universe over I2C:
+// -tags dual for two modules at 0x3C and 0x3D side by side, or -tags
+// tca9548a for modules that share an address, each on its own channel of
+// a TCA9548A multiplexer (see panels_tca9548a.go for the layout).
+//
+// OpenDisplay sets up whichever is chosen.
+//
+// The serial port carries the log, at Info and above; -tags debug logs
+// everything. -tags telemetry adds a JSON record per line with the
+// games' numbers, and -tags "telemetry cbor" sends them as CBOR instead.
+// ConfigureSerial applies these. The same port takes commands (Serial).
+package board
+
+import "machine"
+
+// Button pins. Every button connects its pin to GND and uses the internal
+// pull-up.
+const (
+	ButtonPin = machine.GPIO18 // the single button, select, or the encoder's push switch
+	UpPin     = machine.GPIO25 // three-button layout
+	DownPin   = machine.GPIO26 // three-button layout
+)
+
+// KY-040 rotary encoder pins (SW goes to ButtonPin)
+const (
+	EncoderCLK = machine.GPIO25
+	EncoderDT  = machine.GPIO26
+)
@@ -0,0 +1,8 @@
+//go:build tinygo && oled128x32 && !sh1106 && !ssd1309
+
+package board
+
+import "gameoflife/display"
+
+// Controller is a 0.91" 128x32 SSD1306 module
+var Controller = display.SSD1306x32
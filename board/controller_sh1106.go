@@ -0,0 +1,8 @@
+//go:build tinygo && sh1106
+
+package board
+
+import "gameoflife/display"
+
+// Controller is a 1.3" SH1106 module
+var Controller = display.SH1106
@@ -0,0 +1,8 @@
+//go:build tinygo && !sh1106 && !ssd1309 && !oled128x32
+
+package board
+
+import "gameoflife/display"
+
+// Controller is the common 0.96" 128x64 SSD1306 module (the default)
+var Controller = display.SSD1306
@@ -0,0 +1,8 @@
+//go:build tinygo && ssd1309 && !sh1106
+
+package board
+
+import "gameoflife/display"
+
+// Controller is an SSD1309 module
+var Controller = display.SSD1309
@@ -0,0 +1,29 @@
+//go:build tinygo
+
+package board
+
+import (
+	"fmt"
+
+	"gameoflife/display"
+)
+
+// OpenDisplay sets up the OLED module chosen by the build tags and returns
+// it as a Display that only sends what changed. When the build has several
+// panels (see Panels) they are tiled into one Display, PanelColumns to a
+// row.
+func OpenDisplay() (display.Display, error) {
+	links := displayLinks()
+	panels := make([]display.Display, len(links))
+	for i, link := range links {
+		p, err := display.Open(link, Controller)
+		if err != nil {
+			return nil, fmt.Errorf("board: panel %d: %w", i, err)
+		}
+		panels[i] = p
+	}
+	if len(panels) == 1 {
+		return panels[0], nil
+	}
+	return display.NewTiled(PanelColumns, panels...)
+}
@@ -0,0 +1,32 @@
+//go:build tinygo && !spi
+
+package board
+
+import (
+	"machine"
+
+	"gameoflife/display"
+)
+
+// Transport names how the display is connected in this build
+const Transport = "I2C"
+
+// I2C display wiring
+const (
+	DisplaySDA     = machine.GPIO21
+	DisplaySCL     = machine.GPIO22
+	DisplayAddress = 0x3C // 0x3D on modules with the address jumper moved
+)
+
+// WakePin wakes the board from deep sleep. GPIO18 can't (it isn't an RTC
+// GPIO), so wire GPIO4 to GPIO18 as well: the button then pulls both low.
+const WakePin = machine.GPIO4
+
+func displayLinks() []display.Link {
+	machine.I2C0.Configure(machine.I2CConfig{
+		Frequency: machine.TWI_FREQ_400KHZ,
+		SDA:       DisplaySDA,
+		SCL:       DisplaySCL,
+	})
+	return panelLinks(machine.I2C0)
+}
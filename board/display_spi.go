@@ -0,0 +1,43 @@
+//go:build tinygo && spi
+
+package board
+
+import (
+	"machine"
+
+	"gameoflife/display"
+)
+
+// Transport names how the display is connected in this build
+const Transport = "SPI"
+
+// One panel on SPI; tiling needs the I2C builds (see panels_*.go)
+const (
+	Panels       = 1
+	PanelColumns = 1
+)
+
+// SPI display wiring, on the HSPI pins (VSPI's clock would be GPIO18,
+// which is the button)
+const (
+	DisplaySCK   = machine.GPIO14 // D0 / CLK
+	DisplaySDO   = machine.GPIO13 // D1 / MOSI
+	DisplayCS    = machine.GPIO15
+	DisplayDC    = machine.GPIO27
+	DisplayReset = machine.GPIO4
+)
+
+// WakePin would wake the board from deep sleep, but the SPI display takes
+// GPIO4 and the other free RTC GPIOs are boot strapping pins, so SPI
+// builds don't deep sleep: Sleep idles with the panel off instead.
+const WakePin = machine.NoPin
+
+func displayLinks() []display.Link {
+	machine.SPI0.Configure(machine.SPIConfig{
+		Frequency: 8000000,
+		SCK:       DisplaySCK,
+		SDO:       DisplaySDO,
+		Mode:      0,
+	})
+	return []display.Link{display.NewSPILink(machine.SPI0, DisplayDC, DisplayCS, DisplayReset)}
+}
@@ -0,0 +1,9 @@
+//go:build tinygo && debug
+
+package board
+
+import "gameoflife/log"
+
+// LogLevel is the lowest level logged in this build: everything, for
+// -tags debug
+const LogLevel = log.Debug
@@ -0,0 +1,8 @@
+//go:build tinygo && !debug
+
+package board
+
+import "gameoflife/log"
+
+// LogLevel is the lowest level logged in this build
+const LogLevel = log.Info
@@ -0,0 +1,16 @@
+//go:build tinygo && !encoder && !buttons
+
+package board
+
+import (
+	"gameoflife/input"
+	"gameoflife/ui"
+)
+
+// Controls names the navigation scheme in this build
+const Controls = "single button"
+
+// Navigator reads the single button on GPIO18
+func Navigator() ui.Navigator {
+	return ui.NewButtonNav(input.NewPin(ButtonPin))
+}
@@ -0,0 +1,16 @@
+//go:build tinygo && buttons
+
+package board
+
+import (
+	"gameoflife/input"
+	"gameoflife/ui"
+)
+
+// Controls names the navigation scheme in this build
+const Controls = "up/down/select buttons"
+
+// Navigator reads the up, down and select buttons
+func Navigator() ui.Navigator {
+	return ui.NewButtonsNav(input.NewPin(UpPin), input.NewPin(DownPin), input.NewPin(ButtonPin))
+}
@@ -0,0 +1,24 @@
+//go:build tinygo && encoder && !buttons
+
+package board
+
+import (
+	"gameoflife/input"
+	"gameoflife/log"
+	"gameoflife/ui"
+)
+
+// Controls names the navigation scheme in this build
+const Controls = "rotary encoder"
+
+// Navigator reads the KY-040 encoder and its push switch. If the pins
+// can't raise interrupts the push switch works as a single button.
+func Navigator() ui.Navigator {
+	push := input.NewPin(ButtonPin)
+	encoder, err := input.NewPinEncoder(EncoderCLK, EncoderDT)
+	if err != nil {
+		log.New("INIT").Warn("Encoder unavailable, using single button:", err)
+		return ui.NewButtonNav(push)
+	}
+	return ui.NewEncoderNav(encoder, push)
+}
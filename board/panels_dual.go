@@ -0,0 +1,25 @@
+//go:build tinygo && !spi && dual && !tca9548a
+
+package board
+
+import (
+	"tinygo.org/x/drivers"
+
+	"gameoflife/display"
+)
+
+// Two panels side by side on the one bus: the left one at DisplayAddress
+// and the right one with its address jumper moved
+const (
+	Panels       = 2
+	PanelColumns = 2
+
+	SecondDisplayAddress = 0x3D
+)
+
+func panelLinks(bus drivers.I2C) []display.Link {
+	return []display.Link{
+		display.NewI2CLink(bus, DisplayAddress),
+		display.NewI2CLink(bus, SecondDisplayAddress),
+	}
+}
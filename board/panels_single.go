@@ -0,0 +1,19 @@
+//go:build tinygo && !spi && !dual && !tca9548a
+
+package board
+
+import (
+	"tinygo.org/x/drivers"
+
+	"gameoflife/display"
+)
+
+// One panel (the default)
+const (
+	Panels       = 1
+	PanelColumns = 1
+)
+
+func panelLinks(bus drivers.I2C) []display.Link {
+	return []display.Link{display.NewI2CLink(bus, DisplayAddress)}
+}
@@ -0,0 +1,28 @@
+//go:build tinygo && !spi && tca9548a
+
+package board
+
+import (
+	"tinygo.org/x/drivers"
+
+	"gameoflife/display"
+)
+
+// Panels behind a TCA9548A multiplexer, all at DisplayAddress, panel n on
+// channel n. Four make a 2x2 square; change Panels and PanelColumns for
+// up to eight in other layouts.
+const (
+	Panels       = 4
+	PanelColumns = 2
+
+	MuxAddress = display.MuxAddress
+)
+
+func panelLinks(bus drivers.I2C) []display.Link {
+	mux := display.NewTCA9548A(bus, MuxAddress)
+	links := make([]display.Link, Panels)
+	for i := range links {
+		links[i] = display.NewI2CLink(mux.Channel(i), DisplayAddress)
+	}
+	return links
+}
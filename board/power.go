@@ -0,0 +1,39 @@
+//go:build tinygo
+
+package board
+
+import (
+	"machine"
+	"time"
+
+	"gameoflife/input"
+	"gameoflife/log"
+	"gameoflife/power"
+)
+
+var powerLog = log.New("POWER")
+
+// idlePoll is how often an idling board looks at the button
+const idlePoll = 100 * time.Millisecond
+
+// Sleep puts the board into deep sleep until the button is pressed (see
+// WakePin), which starts the firmware again. Builds without a wake pin
+// (SPI, where GPIO4 is the display's reset) idle instead: the game stops
+// with the panel off until the button on ButtonPin is pressed and let go,
+// and Sleep returns.
+func Sleep() {
+	if WakePin == machine.NoPin {
+		powerLog.Info("No deep sleep in", Transport, "builds: idling until the button is pressed")
+	} else if err := power.DeepSleep(WakePin); err != nil {
+		powerLog.Warn("No deep sleep:", err, "- idling until the button is pressed")
+	}
+
+	button := input.NewPin(ButtonPin)
+	for !button.Pressed() {
+		time.Sleep(idlePoll)
+	}
+	// The press only wakes the board
+	for button.Pressed() {
+		time.Sleep(idlePoll)
+	}
+}
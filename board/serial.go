@@ -0,0 +1,24 @@
+//go:build tinygo
+
+package board
+
+import (
+	"io"
+	"machine"
+
+	"gameoflife/log"
+	"gameoflife/telemetry"
+)
+
+// ConfigureSerial sets up the serial output for this build: the log level
+// and the telemetry format
+func ConfigureSerial() {
+	log.SetLevel(LogLevel)
+	telemetry.SetFormat(TelemetryFormat)
+}
+
+// Serial is the USB serial port, for the command console. The log and
+// the telemetry go out on it too.
+func Serial() io.ReadWriter {
+	return machine.Serial
+}
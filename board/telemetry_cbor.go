@@ -0,0 +1,9 @@
+//go:build tinygo && telemetry && cbor
+
+package board
+
+import "gameoflife/telemetry"
+
+// TelemetryFormat is how telemetry is sent in this build: tagged CBOR
+// maps, for -tags "telemetry cbor"
+const TelemetryFormat = telemetry.CBOR
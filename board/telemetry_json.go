@@ -0,0 +1,9 @@
+//go:build tinygo && telemetry && !cbor
+
+package board
+
+import "gameoflife/telemetry"
+
+// TelemetryFormat is how telemetry is sent in this build: a JSON object
+// per line
+const TelemetryFormat = telemetry.JSON
@@ -0,0 +1,8 @@
+//go:build tinygo && !telemetry
+
+package board
+
+import "gameoflife/telemetry"
+
+// TelemetryFormat is how telemetry is sent in this build: not at all
+const TelemetryFormat = telemetry.Off
@@ -0,0 +1,266 @@
+// Package console is a command shell on the serial port, so the board
+// can be driven from a computer as well as with its buttons.
+//
+// Commands are lines of words, such as "speed 50" or "pattern glider".
+// Every command is answered with any output it has, then a line "ok" or
+// "error: " and what went wrong (the lines sent are marked >):
+//
+//	> rule B36/S23
+//	ok
+//	> pattern nonesuch
+//	error: life: no pattern called "nonesuch"
+//
+// A command declared with Body reads the lines after it, up to one with
+// a '!' in it (other than a # comment), which ends an RLE pattern:
+//
+//	> load
+//	> x = 3, y = 3
+//	> bob$2bo$3o!
+//	ok
+//
+// On the serial port the console shares the line with the log and the
+// telemetry; it can take commands from other ports (the web server's) at
+// the same time. It never waits for input: the game polls it once a
+// frame with Next and answers the requests it returns with Print and
+// Done. help, log and telemetry are answered by the console itself.
+package console
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"gameoflife/log"
+	"gameoflife/telemetry"
+)
+
+// Longest line and body a console accepts; anything longer is answered
+// with an error and dropped
+const (
+	MaxLine = 256
+	MaxBody = 8192
+)
+
+// Command describes a command a game answers, for help and for parsing
+type Command struct {
+	Name  string
+	Usage string // the arguments, such as "<name>"
+	Help  string
+	Body  bool // followed by lines up to one with '!' (an RLE pattern)
+}
+
+// builtins are the commands the console answers itself
+var builtins = []Command{
+	{Name: "help", Help: "list the commands"},
+	{Name: "log", Usage: "[tag] <level>", Help: "log debug, info, warn, error or off"},
+	{Name: "telemetry", Usage: "off|json|cbor", Help: "start or stop telemetry"},
+}
+
+// Request is a command read from the port, for the game to carry out
+type Request struct {
+	Name string   // lower case
+	Args []string // the words after the name
+	Body string   // for a Command with Body: its lines
+}
+
+// Arg returns argument i, or "" if there are fewer
+func (r Request) Arg(i int) string {
+	if i < len(r.Args) {
+		return r.Args[i]
+	}
+	return ""
+}
+
+// Console reads commands from one or more ports and writes the answers
+// back to the port each came from
+type Console struct {
+	commands []Command
+	ports    []*port
+	next     int   // the port to read first, taking turns
+	current  *port // the port being answered
+}
+
+// port is one way in, with its own half-read line and body
+type port struct {
+	rw      io.ReadWriter
+	chunk   [64]byte
+	pending []byte   // read but not yet split into lines
+	long    bool     // dropping the rest of an overlong line
+	body    *Request // reading this request's body
+}
+
+// New creates a console answering commands as well as the built-in ones
+// on ports, such as the serial port. Reading from a port must not wait
+// when nothing has arrived, as with a UART: it returns 0 bytes. Nil
+// ports are left out; a console without any never has any requests.
+func New(commands []Command, ports ...io.ReadWriter) *Console {
+	c := &Console{commands: commands}
+	for _, rw := range ports {
+		if rw != nil {
+			c.ports = append(c.ports, &port{rw: rw})
+		}
+	}
+	return c
+}
+
+// Next returns the next request that has arrived for the game, answering
+// the built-in commands and malformed ones on the way. It returns false
+// when no complete request is waiting.
+func (c *Console) Next() (Request, bool) {
+	for i := range c.ports {
+		n := (c.next + i) % len(c.ports)
+		c.current = c.ports[n]
+		for {
+			line, ok := c.readLine()
+			if !ok {
+				break
+			}
+			if req, ok := c.handle(line); ok {
+				c.next = n + 1
+				return req, true
+			}
+		}
+	}
+	return Request{}, false
+}
+
+// Print writes a line of output for the request being answered
+func (c *Console) Print(args ...any) {
+	if c.current != nil {
+		fmt.Fprintln(c.current.rw, args...)
+	}
+}
+
+// Done ends the answer to a request: "ok" if err is nil, else the error
+func (c *Console) Done(err error) {
+	if err != nil {
+		c.Print("error: " + err.Error())
+		return
+	}
+	c.Print("ok")
+}
+
+// readLine returns the next whole line that has arrived on the current
+// port. Lines end with CR, LF or both, so serial monitors that send
+// either work.
+func (c *Console) readLine() (string, bool) {
+	p := c.current
+	for {
+		if i := bytes.IndexAny(p.pending, "\r\n"); i >= 0 {
+			line := string(p.pending[:i])
+			p.pending = p.pending[:copy(p.pending, p.pending[i+1:])]
+			if p.long || len(line) > MaxLine {
+				p.long = false
+				c.Done(fmt.Errorf("console: line longer than %d bytes", MaxLine))
+				continue
+			}
+			return line, true
+		}
+		if len(p.pending) > MaxLine {
+			p.pending = p.pending[:0]
+			p.long = true
+		}
+		n, _ := p.rw.Read(p.chunk[:])
+		if n == 0 {
+			return "", false
+		}
+		p.pending = append(p.pending, p.chunk[:n]...)
+	}
+}
+
+// handle deals with a line from the current port, returning a request if
+// it completes one for the game
+func (c *Console) handle(line string) (Request, bool) {
+	p := c.current
+	line = strings.TrimSpace(line)
+
+	if req := p.body; req != nil {
+		if len(req.Body)+len(line) > MaxBody {
+			p.body = nil
+			c.Done(fmt.Errorf("console: %s: longer than %d bytes", req.Name, MaxBody))
+			return Request{}, false
+		}
+		req.Body += line + "\n"
+		if strings.HasPrefix(line, "#") || !strings.Contains(line, "!") {
+			return Request{}, false
+		}
+		p.body = nil
+		return *req, true
+	}
+
+	words := strings.Fields(line)
+	if len(words) == 0 {
+		return Request{}, false
+	}
+	req := Request{Name: strings.ToLower(words[0]), Args: words[1:]}
+	switch req.Name {
+	case "help":
+		c.help()
+		return Request{}, false
+	case "log":
+		c.Done(setLog(req.Args))
+		return Request{}, false
+	case "telemetry":
+		c.Done(setTelemetry(req.Args))
+		return Request{}, false
+	}
+	for _, cmd := range c.commands {
+		if cmd.Name != req.Name {
+			continue
+		}
+		if cmd.Body {
+			p.body = &req
+			return Request{}, false
+		}
+		return req, true
+	}
+	c.Done(errors.New("console: unknown command " + strconv.Quote(req.Name) + " (try help)"))
+	return Request{}, false
+}
+
+// help lists the commands, the game's first
+func (c *Console) help() {
+	for _, list := range [][]Command{c.commands, builtins} {
+		for _, cmd := range list {
+			usage := cmd.Name
+			if cmd.Usage != "" {
+				usage += " " + cmd.Usage
+			}
+			c.Print(usage + strings.Repeat(" ", max(1, 28-len(usage))) + cmd.Help)
+		}
+	}
+	c.Done(nil)
+}
+
+// setLog carries out "log [tag] <level>"
+func setLog(args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return errors.New("console: usage: log [tag] <level>")
+	}
+	level, err := log.ParseLevel(args[len(args)-1])
+	if err != nil {
+		return err
+	}
+	if len(args) == 2 {
+		log.SetTagLevel(strings.ToUpper(args[0]), level)
+	} else {
+		log.SetLevel(level)
+	}
+	return nil
+}
+
+// setTelemetry carries out "telemetry <format>"
+func setTelemetry(args []string) error {
+	if len(args) != 1 {
+		return errors.New("console: usage: telemetry off|json|cbor")
+	}
+	format, err := telemetry.ParseFormat(args[0])
+	if err != nil {
+		return err
+	}
+	telemetry.SetFormat(format)
+	return nil
+}
@@ -0,0 +1,228 @@
+package console
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"gameoflife/log"
+	"gameoflife/telemetry"
+)
+
+// testPort is a serial port in memory: the console reads what was sent
+// from in and answers into out
+type testPort struct {
+	in, out bytes.Buffer
+}
+
+func (p *testPort) Read(b []byte) (int, error)  { return p.in.Read(b) }
+func (p *testPort) Write(b []byte) (int, error) { return p.out.Write(b) }
+
+// answer returns the lines written since the last call
+func (p *testPort) answer() []string {
+	out := strings.TrimSuffix(p.out.String(), "\n")
+	p.out.Reset()
+	if out == "" {
+		return nil
+	}
+	return strings.Split(out, "\n")
+}
+
+var testCommands = []Command{
+	{Name: "speed", Usage: "<ms>", Help: "set the speed"},
+	{Name: "load", Help: "load an RLE pattern", Body: true},
+}
+
+// requests reads every request waiting, answering each with ok
+func requests(c *Console) []Request {
+	var reqs []Request
+	for req, ok := c.Next(); ok; req, ok = c.Next() {
+		reqs = append(reqs, req)
+		c.Done(nil)
+	}
+	return reqs
+}
+
+func TestConsoleLineEndings(t *testing.T) {
+	for _, tt := range []struct{ name, end string }{
+		{"CR", "\r"},
+		{"LF", "\n"},
+		{"CRLF", "\r\n"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &testPort{}
+			c := New(testCommands, p)
+			p.in.WriteString("SPEED 50" + tt.end + "speed 60" + tt.end)
+			reqs := requests(c)
+			if len(reqs) != 2 {
+				t.Fatalf("got %d requests, want 2: %v", len(reqs), reqs)
+			}
+			for i, want := range []string{"50", "60"} {
+				if reqs[i].Name != "speed" || reqs[i].Arg(0) != want {
+					t.Errorf("request %d is %+v, want speed %s", i, reqs[i], want)
+				}
+			}
+			if got := p.answer(); strings.Join(got, ",") != "ok,ok" {
+				t.Errorf("answered %q", got)
+			}
+		})
+	}
+}
+
+func TestConsolePartialLine(t *testing.T) {
+	p := &testPort{}
+	c := New(testCommands, p)
+	p.in.WriteString("spe")
+	if _, ok := c.Next(); ok {
+		t.Fatal("request before the line ended")
+	}
+	p.in.WriteString("ed 70\n")
+	if req, ok := c.Next(); !ok || req.Arg(0) != "70" {
+		t.Errorf("got %+v, %v", req, ok)
+	}
+}
+
+func TestConsoleLongLine(t *testing.T) {
+	for _, n := range []int{MaxLine + 1, MaxLine + 63, 4 * MaxLine} {
+		p := &testPort{}
+		c := New(testCommands, p)
+		p.in.WriteString("speed " + strings.Repeat("9", n-len("speed ")) + "\nspeed 80\n")
+		reqs := requests(c)
+		if len(reqs) != 1 || reqs[0].Arg(0) != "80" {
+			t.Errorf("%d bytes: got requests %v, want only speed 80", n, reqs)
+		}
+		want := []string{"error: console: line longer than 256 bytes", "ok"}
+		if got := p.answer(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Errorf("%d bytes: answered %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestConsoleBody(t *testing.T) {
+	p := &testPort{}
+	c := New(testCommands, p)
+	p.in.WriteString("load\n#N Glider\nx = 3, y = 3\n# not the end!\nbo$2bo$3o!\n")
+	reqs := requests(c)
+	if len(reqs) != 1 || reqs[0].Name != "load" {
+		t.Fatalf("got requests %v", reqs)
+	}
+	want := "#N Glider\nx = 3, y = 3\n# not the end!\nbo$2bo$3o!\n"
+	if reqs[0].Body != want {
+		t.Errorf("body %q, want %q", reqs[0].Body, want)
+	}
+}
+
+func TestConsoleLongBody(t *testing.T) {
+	p := &testPort{}
+	c := New(testCommands, p)
+	p.in.WriteString("load\n")
+	line := strings.Repeat("b", 200) + "\n"
+	for n := 0; n <= MaxBody; n += len(line) {
+		p.in.WriteString(line)
+	}
+	p.in.WriteString("speed 90\n")
+	reqs := requests(c)
+	if len(reqs) != 1 || reqs[0].Name != "speed" {
+		t.Errorf("got requests %v, want only speed 90", reqs)
+	}
+	want := []string{"error: console: load: longer than 8192 bytes", "ok"}
+	if got := p.answer(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("answered %q, want %q", got, want)
+	}
+}
+
+func TestConsoleBuiltins(t *testing.T) {
+	t.Cleanup(func() {
+		log.SetLevel(log.Info)
+		log.SetTagLevel("CONSOLETEST", log.Info)
+		telemetry.SetFormat(telemetry.Off)
+	})
+	p := &testPort{}
+	c := New(testCommands, p)
+
+	p.in.WriteString("help\n")
+	if reqs := requests(c); len(reqs) != 0 {
+		t.Errorf("help reached the game: %v", reqs)
+	}
+	help := p.answer()
+	if len(help) != len(testCommands)+len(builtins)+1 || help[len(help)-1] != "ok" {
+		t.Errorf("help answered %q", help)
+	}
+	if !strings.HasPrefix(help[0], "speed <ms>") || !strings.HasSuffix(help[0], "set the speed") {
+		t.Errorf("help line %q", help[0])
+	}
+
+	tests := []struct {
+		line  string
+		want  string
+		check func() bool
+	}{
+		{"log consoletest debug", "ok", func() bool { return log.New("CONSOLETEST").Enabled(log.Debug) }},
+		{"log warn", "ok", func() bool { return !log.New("OTHER").Enabled(log.Info) }},
+		{"log loud", "error: log: unknown level \"loud\"", nil},
+		{"log", "error: console: usage: log [tag] <level>", nil},
+		{"telemetry json", "ok", telemetry.Enabled},
+		{"telemetry off", "ok", func() bool { return !telemetry.Enabled() }},
+		{"telemetry xml", "error: telemetry: unknown format \"xml\"", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			p.in.WriteString(tt.line + "\n")
+			if reqs := requests(c); len(reqs) != 0 {
+				t.Errorf("reached the game: %v", reqs)
+			}
+			if got := p.answer(); len(got) != 1 || got[0] != tt.want {
+				t.Errorf("answered %q, want %q", got, tt.want)
+			}
+			if tt.check != nil && !tt.check() {
+				t.Error("not carried out")
+			}
+		})
+	}
+}
+
+func TestConsoleUnknown(t *testing.T) {
+	p := &testPort{}
+	c := New(testCommands, p)
+	p.in.WriteString("\n   \nfly away\n")
+	if reqs := requests(c); len(reqs) != 0 {
+		t.Errorf("got requests %v", reqs)
+	}
+	want := `error: console: unknown command "fly" (try help)`
+	if got := p.answer(); len(got) != 1 || got[0] != want {
+		t.Errorf("answered %q, want %q", got, want)
+	}
+}
+
+func TestConsolePorts(t *testing.T) {
+	serial, web := &testPort{}, &testPort{}
+	c := New(testCommands, nil, serial, web)
+	serial.in.WriteString("speed 1\nspeed 2\n")
+	web.in.WriteString("load\nx = 1, y = 1\no!\nspeed 3\n")
+
+	// The ports take turns, and each is answered on its own
+	var got []string
+	for req, ok := c.Next(); ok; req, ok = c.Next() {
+		got = append(got, req.Name+" "+req.Arg(0))
+		c.Print(req.Name)
+		c.Done(nil)
+	}
+	want := []string{"speed 1", "load ", "speed 2", "speed 3"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("requests %q, want %q", got, want)
+	}
+	if got := serial.answer(); strings.Join(got, ",") != "speed,ok,speed,ok" {
+		t.Errorf("serial port answered %q", got)
+	}
+	if got := web.answer(); strings.Join(got, ",") != "load,ok,speed,ok" {
+		t.Errorf("web port answered %q", got)
+	}
+}
+
+func TestConsoleNoPorts(t *testing.T) {
+	c := New(testCommands, nil)
+	if req, ok := c.Next(); ok {
+		t.Errorf("got %+v", req)
+	}
+	c.Done(nil) // nowhere to answer, but mustn't fail
+}
@@ -0,0 +1,129 @@
+package display
+
+import "fmt"
+
+// SSD1306-family commands used to set a controller up
+const (
+	displayOff = 0xAE
+	displayOn  = 0xAF
+	clockDiv   = 0xD5
+	multiplex  = 0xA8
+	dispOffset = 0xD3
+	startLine  = 0x40
+	chargePump = 0x8D
+	memoryMode = 0x20
+	segRemap   = 0xA1 // column 127 is SEG0: with comScanDec, the panel is upright
+	comScanDec = 0xC8
+	comPins    = 0xDA
+	preCharge  = 0xD9
+	vcomDetect = 0xDB
+	resumeRAM  = 0xA4
+	normal     = 0xA6
+	stopScroll = 0x2E
+	sh1106DCDC = 0xAD // SH1106 DC-DC converter control
+	sh1106Pump = 0x32 // SH1106 pump voltage 8.0V
+	pageStart  = 0xB0 // page addressing: | page
+	columnLow  = 0x00 // page addressing: | low nibble of the column
+	columnHigh = 0x10 // page addressing: | high nibble of the column
+)
+
+// Controller describes an OLED controller and panel: its size, how its
+// RAM is addressed and the commands that set it up
+type Controller struct {
+	Name          string
+	Width, Height int16
+
+	// ColumnOffset is where the panel's first column sits in the
+	// controller's RAM. The SH1106 has 132 columns of RAM for a 128
+	// column panel, centred, so it is 2 there.
+	ColumnOffset int
+
+	// PageAddressing means the controller can't write a window that
+	// spans pages (the SH1106 has no horizontal addressing mode), so
+	// every page is addressed on its own
+	PageAddressing bool
+
+	// Init sets the controller up, leaving the display off
+	Init []byte
+}
+
+// The supported controllers
+var (
+	// SSD1306 is the common 0.96" 128x64 module
+	SSD1306 = Controller{
+		Name: "ssd1306", Width: 128, Height: 64,
+		Init: []byte{
+			displayOff, clockDiv, 0x80, multiplex, 63, dispOffset, 0, startLine,
+			chargePump, 0x14, memoryMode, 0x00, segRemap, comScanDec,
+			comPins, 0x12, setContrast, 0xCF, preCharge, 0xF1, vcomDetect, 0x40,
+			resumeRAM, normal, stopScroll,
+		},
+	}
+
+	// SSD1306x32 is the 0.91" 128x32 module
+	SSD1306x32 = Controller{
+		Name: "ssd1306-128x32", Width: 128, Height: 32,
+		Init: []byte{
+			displayOff, clockDiv, 0x80, multiplex, 31, dispOffset, 0, startLine,
+			chargePump, 0x14, memoryMode, 0x00, segRemap, comScanDec,
+			comPins, 0x02, setContrast, 0x8F, preCharge, 0xF1, vcomDetect, 0x40,
+			resumeRAM, normal, stopScroll,
+		},
+	}
+
+	// SSD1309 is the 1.3"/1.54" 128x64 module. Its panel supply comes from
+	// the module, so there is no charge pump to enable.
+	SSD1309 = Controller{
+		Name: "ssd1309", Width: 128, Height: 64,
+		Init: []byte{
+			displayOff, clockDiv, 0xA0, multiplex, 63, dispOffset, 0, startLine,
+			memoryMode, 0x00, segRemap, comScanDec,
+			comPins, 0x12, setContrast, 0xCF, preCharge, 0xF1, vcomDetect, 0x34,
+			resumeRAM, normal, stopScroll,
+		},
+	}
+
+	// SH1106 is the 1.3" 128x64 module sold alongside the SSD1306 ones.
+	// It looks the same but has 132 columns of RAM and page addressing
+	// only.
+	SH1106 = Controller{
+		Name: "sh1106", Width: 128, Height: 64,
+		ColumnOffset:   2,
+		PageAddressing: true,
+		Init: []byte{
+			displayOff, clockDiv, 0x80, multiplex, 63, dispOffset, 0, startLine,
+			sh1106DCDC, 0x8B, segRemap, comScanDec,
+			comPins, 0x12, setContrast, 0x80, preCharge, 0x1F, vcomDetect, 0x40,
+			sh1106Pump, resumeRAM, normal,
+		},
+	}
+)
+
+// Controllers lists the supported controllers
+var Controllers = []Controller{SSD1306, SSD1306x32, SSD1309, SH1106}
+
+// ControllerByName finds a controller by its Name
+func ControllerByName(name string) (Controller, error) {
+	for _, c := range Controllers {
+		if c.Name == name {
+			return c, nil
+		}
+	}
+	return Controller{}, fmt.Errorf("display: unknown controller %q", name)
+}
+
+// Open sets up the controller on link, clears the panel, turns it on and
+// returns a Partial display for it
+func Open(link Link, c Controller) (*Partial, error) {
+	if err := link.Commands(c.Init...); err != nil {
+		return nil, fmt.Errorf("display: %s setup failed: %w", c.Name, err)
+	}
+	p := NewPartial(link, c)
+	if err := p.Display(); err != nil { // the first flush writes all of RAM
+		return nil, fmt.Errorf("display: %s clear failed: %w", c.Name, err)
+	}
+	if err := link.Commands(displayOn); err != nil {
+		return nil, fmt.Errorf("display: %s power on failed: %w", c.Name, err)
+	}
+	return p, nil
+}
@@ -0,0 +1,175 @@
+package display
+
+import (
+	"bytes"
+	"testing"
+
+	"gameoflife/ssd1306emu"
+)
+
+// recordingLink passes everything on to an emulated controller and keeps
+// the commands sent
+type recordingLink struct {
+	Link
+	commands [][]byte
+}
+
+func (l *recordingLink) Commands(cmds ...byte) error {
+	l.commands = append(l.commands, bytes.Clone(cmds))
+	return l.Link.Commands(cmds...)
+}
+
+// The init sequences, byte for byte, as the datasheets give them
+var controllerTests = []struct {
+	c          Controller
+	ramWidth   int // the controller's RAM is wider than the panel on the SH1106
+	init       []byte
+	contrast   uint8
+	chargePump bool
+	mode       int
+}{
+	{
+		c: SSD1306, ramWidth: 128,
+		init: []byte{
+			0xAE, 0xD5, 0x80, 0xA8, 0x3F, 0xD3, 0x00, 0x40, 0x8D, 0x14, 0x20, 0x00, 0xA1, 0xC8,
+			0xDA, 0x12, 0x81, 0xCF, 0xD9, 0xF1, 0xDB, 0x40, 0xA4, 0xA6, 0x2E,
+		},
+		contrast: 0xCF, chargePump: true, mode: ssd1306emu.HorizontalMode,
+	},
+	{
+		c: SSD1306x32, ramWidth: 128,
+		init: []byte{
+			0xAE, 0xD5, 0x80, 0xA8, 0x1F, 0xD3, 0x00, 0x40, 0x8D, 0x14, 0x20, 0x00, 0xA1, 0xC8,
+			0xDA, 0x02, 0x81, 0x8F, 0xD9, 0xF1, 0xDB, 0x40, 0xA4, 0xA6, 0x2E,
+		},
+		contrast: 0x8F, chargePump: true, mode: ssd1306emu.HorizontalMode,
+	},
+	{
+		c: SSD1309, ramWidth: 128,
+		init: []byte{
+			0xAE, 0xD5, 0xA0, 0xA8, 0x3F, 0xD3, 0x00, 0x40, 0x20, 0x00, 0xA1, 0xC8,
+			0xDA, 0x12, 0x81, 0xCF, 0xD9, 0xF1, 0xDB, 0x34, 0xA4, 0xA6, 0x2E,
+		},
+		contrast: 0xCF, chargePump: false, mode: ssd1306emu.HorizontalMode,
+	},
+	{
+		c: SH1106, ramWidth: 132,
+		init: []byte{
+			0xAE, 0xD5, 0x80, 0xA8, 0x3F, 0xD3, 0x00, 0x40, 0xAD, 0x8B, 0xA1, 0xC8,
+			0xDA, 0x12, 0x81, 0x80, 0xD9, 0x1F, 0xDB, 0x40, 0x32, 0xA4, 0xA6,
+		},
+		contrast: 0x80, chargePump: false, mode: ssd1306emu.PageMode,
+	},
+}
+
+// openEmulated opens c on an emulated controller
+func openEmulated(t *testing.T, c Controller, ramWidth int) (*Partial, *recordingLink, *ssd1306emu.Device) {
+	t.Helper()
+	bus := ssd1306emu.NewBus()
+	dev := bus.Attach(0x3C, ramWidth, int(c.Height))
+	link := &recordingLink{Link: NewI2CLink(bus, 0x3C)}
+	p, err := Open(link, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p, link, dev
+}
+
+// checkRAM compares the controller's RAM with what was drawn
+func checkRAM(t *testing.T, p *Partial, dev *ssd1306emu.Device) {
+	t.Helper()
+	w, h := p.Size()
+	for y := 0; y < int(h); y++ {
+		for x := 0; x < int(w); x++ {
+			if got, want := dev.RAMPixel(x+p.ctrl.ColumnOffset, y), p.GetPixel(int16(x), int16(y)); got != want {
+				t.Fatalf("RAM pixel (%d, %d) is %v, drew %v", x, y, got, want)
+			}
+		}
+	}
+}
+
+func TestControllerInit(t *testing.T) {
+	for _, tt := range controllerTests {
+		t.Run(tt.c.Name, func(t *testing.T) {
+			_, link, dev := openEmulated(t, tt.c, tt.ramWidth)
+
+			if !bytes.Equal(link.commands[0], tt.init) {
+				t.Errorf("init sent % X\nwant % X", link.commands[0], tt.init)
+			}
+			if last := link.commands[len(link.commands)-1]; !bytes.Equal(last, []byte{0xAF}) {
+				t.Errorf("last command % X, want AF (display on)", last)
+			}
+			if !dev.On() {
+				t.Error("display is off after Open")
+			}
+			if dev.Contrast() != tt.contrast {
+				t.Errorf("contrast %#02x, want %#02x", dev.Contrast(), tt.contrast)
+			}
+			if dev.ChargePump() != tt.chargePump {
+				t.Errorf("charge pump %v, want %v", dev.ChargePump(), tt.chargePump)
+			}
+			if dev.AddressingMode() != tt.mode {
+				t.Errorf("addressing mode %d, want %d", dev.AddressingMode(), tt.mode)
+			}
+			if dev.Inverted() || dev.Scrolling() {
+				t.Error("display inverted or scrolling after Open")
+			}
+		})
+	}
+}
+
+func TestControllerPageWrites(t *testing.T) {
+	for _, tt := range controllerTests {
+		t.Run(tt.c.Name, func(t *testing.T) {
+			p, _, dev := openEmulated(t, tt.c, tt.ramWidth)
+			w, h := p.Size()
+
+			// A border and a diagonal cross every page and both edges
+			for x := int16(0); x < w; x++ {
+				p.SetPixel(x, 0, White)
+				p.SetPixel(x, h-1, White)
+			}
+			for y := int16(0); y < h; y++ {
+				p.SetPixel(0, y, White)
+				p.SetPixel(w-1, y, White)
+				p.SetPixel(y*2, y, White)
+			}
+			if err := p.Display(); err != nil {
+				t.Fatal(err)
+			}
+			checkRAM(t, p, dev)
+
+			// The panel shows it upright. The emulator doesn't know about the
+			// SH1106's column offset, so only its RAM is checked.
+			if tt.c.ColumnOffset == 0 {
+				for y := 0; y < int(h); y++ {
+					for x := 0; x < int(w); x++ {
+						if got, want := dev.Pixel(x, y), p.GetPixel(int16(x), int16(y)); got != want {
+							t.Fatalf("panel pixel (%d, %d) is %v, drew %v", x, y, got, want)
+						}
+					}
+				}
+			}
+
+			// One changed pixel in the far corner sends one byte
+			dev.ResetStats()
+			p.SetPixel(w-2, h-2, White)
+			if err := p.Display(); err != nil {
+				t.Fatal(err)
+			}
+			checkRAM(t, p, dev)
+			if n := dev.Stats().DataBytes; n != 1 {
+				t.Errorf("sent %d data bytes for one changed byte, want 1", n)
+			}
+
+			// An unchanged frame sends nothing
+			dev.ResetStats()
+			if err := p.Display(); err != nil {
+				t.Fatal(err)
+			}
+			if n := dev.Stats().Transactions; n != 0 {
+				t.Errorf("%d transactions for an unchanged frame, want 0", n)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+// Package display is the drawing surface shared by the OLED games.
+//
+// The games draw on a Display instead of a *ssd1306.Device, so the same
+// code runs on the ESP32, in a terminal on a laptop and in tests.
+package display
+
+import (
+	"image/color"
+
+	"tinygo.org/x/drivers/ssd1306"
+)
+
+// Display is a monochrome pixel display with an off-screen buffer.
+// It's a drivers.Displayer (so tinyfont can draw on it) plus ClearBuffer.
+type Display interface {
+	// Size returns the display size in pixels
+	Size() (x, y int16)
+
+	// SetPixel sets a pixel in the buffer; black turns it off, any other
+	// colour turns it on
+	SetPixel(x, y int16, c color.RGBA)
+
+	// ClearBuffer turns every pixel in the buffer off
+	ClearBuffer()
+
+	// Display flushes the buffer to the screen
+	Display() error
+}
+
+// Pixel colours
+var (
+	White = color.RGBA{255, 255, 255, 255}
+	Black = color.RGBA{0, 0, 0, 255}
+)
+
+// The SSD1306 driver is a Display as it is
+var _ Display = (*ssd1306.Device)(nil)
+
+// Commander is a display that takes raw SSD1306 commands, as the driver
+// does
+type Commander interface {
+	Command(cmd uint8)
+}
+
+var _ Commander = (*ssd1306.Device)(nil)
+
+// SSD1306 commands used outside the driver
+const (
+	setContrast = 0x81
+)
+
+// SetContrast sets the brightness of an SSD1306 (1-255; the panel is
+// still lit at 1). Displays that don't take commands are left as they
+// are, and false is returned.
+func SetContrast(d Display, contrast uint8) bool {
+	c, ok := d.(Commander)
+	if !ok {
+		return false
+	}
+	c.Command(setContrast)
+	c.Command(contrast)
+	return true
+}
+
+// SetPower turns an SSD1306 panel off (its RAM is kept, and it draws a few
+// microamps) or back on. Displays that don't take commands are left as
+// they are, and false is returned.
+func SetPower(d Display, on bool) bool {
+	c, ok := d.(Commander)
+	if !ok {
+		return false
+	}
+	if on {
+		c.Command(displayOn)
+	} else {
+		c.Command(displayOff)
+	}
+	return true
+}
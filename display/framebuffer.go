@@ -0,0 +1,69 @@
+package display
+
+import "image/color"
+
+// Framebuffer is an in-memory Display.
+// Pixels are packed the way the SSD1306 stores them: one byte covers a
+// column of 8 pixels in a page, least significant bit at the top.
+type Framebuffer struct {
+	width   int16
+	height  int16
+	buffer  []byte
+	flushes int
+}
+
+// NewFramebuffer creates a blank framebuffer
+func NewFramebuffer(width, height int16) *Framebuffer {
+	return &Framebuffer{
+		width:  width,
+		height: height,
+		buffer: make([]byte, int(width)*((int(height)+7)/8)),
+	}
+}
+
+// Size returns the framebuffer size in pixels
+func (fb *Framebuffer) Size() (x, y int16) {
+	return fb.width, fb.height
+}
+
+// SetPixel turns a pixel on or off; pixels outside the buffer are ignored
+func (fb *Framebuffer) SetPixel(x, y int16, c color.RGBA) {
+	if x < 0 || x >= fb.width || y < 0 || y >= fb.height {
+		return
+	}
+	i := int(x) + int(y/8)*int(fb.width)
+	if c.R != 0 || c.G != 0 || c.B != 0 {
+		fb.buffer[i] |= 1 << uint8(y%8)
+	} else {
+		fb.buffer[i] &^= 1 << uint8(y%8)
+	}
+}
+
+// GetPixel reports whether a pixel is on
+func (fb *Framebuffer) GetPixel(x, y int16) bool {
+	if x < 0 || x >= fb.width || y < 0 || y >= fb.height {
+		return false
+	}
+	return fb.buffer[int(x)+int(y/8)*int(fb.width)]>>uint8(y%8)&1 == 1
+}
+
+// ClearBuffer turns every pixel off
+func (fb *Framebuffer) ClearBuffer() {
+	clear(fb.buffer)
+}
+
+// Display counts the flush; an in-memory buffer has nowhere to send it
+func (fb *Framebuffer) Display() error {
+	fb.flushes++
+	return nil
+}
+
+// Flushes returns how many times Display has been called
+func (fb *Framebuffer) Flushes() int {
+	return fb.flushes
+}
+
+// Buffer returns the packed pixel data (SSD1306 page layout)
+func (fb *Framebuffer) Buffer() []byte {
+	return fb.buffer
+}
@@ -0,0 +1,31 @@
+package display
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestFramebufferSize(t *testing.T) {
+	tests := []struct {
+		width, height int16
+		want          int
+	}{
+		{128, 64, 1024},
+		{128, 32, 512},
+		{132, 64, 1056},
+		{8, 1, 8},
+		{8, 9, 16}, // a partial page still takes a whole one
+	}
+	white := color.RGBA{255, 255, 255, 255}
+	for _, tt := range tests {
+		fb := NewFramebuffer(tt.width, tt.height)
+		if got := len(fb.Buffer()); got != tt.want {
+			t.Errorf("%dx%d: %d bytes, want %d", tt.width, tt.height, got, tt.want)
+		}
+		// The bottom right pixel lands in the last byte
+		fb.SetPixel(tt.width-1, tt.height-1, white)
+		if b := fb.Buffer()[tt.want-1]; b != 1<<uint((tt.height-1)%8) {
+			t.Errorf("%dx%d: last byte %08b after setting the last pixel", tt.width, tt.height, b)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package display
+
+import (
+	"bytes"
+	"image/color"
+	"sync"
+)
+
+// Frame is a flushed frame, as a Mirror passes it on. Pixels are packed
+// in the SSD1306 page layout, as in a Framebuffer.
+type Frame struct {
+	Width, Height int16
+	Pixels        []byte
+	On            bool // false while the panel is turned off
+}
+
+// Mirror is a Display that draws on another (the panel) and keeps a copy
+// of each frame it flushes, for something else to show the same: the web
+// dashboard. Watchers get every frame that differs from the last, as
+// Display sends it; one that falls behind skips to the newest.
+type Mirror struct {
+	panel Display
+	fb    *Framebuffer
+
+	param bool // the next command byte is setContrast's parameter
+
+	mu       sync.Mutex
+	last     Frame
+	watchers map[chan Frame]struct{}
+}
+
+// NewMirror mirrors d
+func NewMirror(d Display) *Mirror {
+	w, h := d.Size()
+	fb := NewFramebuffer(w, h)
+	return &Mirror{
+		panel:    d,
+		fb:       fb,
+		last:     Frame{Width: w, Height: h, Pixels: bytes.Clone(fb.Buffer()), On: true},
+		watchers: map[chan Frame]struct{}{},
+	}
+}
+
+// Size returns the display's size
+func (m *Mirror) Size() (x, y int16) {
+	return m.panel.Size()
+}
+
+// SetPixel sets the pixel on the display and in the copy
+func (m *Mirror) SetPixel(x, y int16, c color.RGBA) {
+	m.panel.SetPixel(x, y, c)
+	m.fb.SetPixel(x, y, c)
+}
+
+// ClearBuffer clears the display's buffer and the copy
+func (m *Mirror) ClearBuffer() {
+	m.panel.ClearBuffer()
+	m.fb.ClearBuffer()
+}
+
+// Display flushes the display, then passes the frame on if it changed
+func (m *Mirror) Display() error {
+	err := m.panel.Display()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !bytes.Equal(m.fb.Buffer(), m.last.Pixels) {
+		m.last.Pixels = bytes.Clone(m.fb.Buffer())
+		m.publish()
+	}
+	return err
+}
+
+// Command passes SSD1306 commands on to the display, if it takes them,
+// noting when the panel is turned off and on
+func (m *Mirror) Command(cmd uint8) {
+	if c, ok := m.panel.(Commander); ok {
+		c.Command(cmd)
+	}
+	if m.param {
+		m.param = false
+		return
+	}
+	m.param = cmd == setContrast
+	if cmd != displayOn && cmd != displayOff {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if on := cmd == displayOn; on != m.last.On {
+		m.last.On = on
+		m.publish()
+	}
+}
+
+// publish sends the last frame to the watchers. Frames are never changed
+// once sent, so they can share the pixels.
+func (m *Mirror) publish() {
+	for ch := range m.watchers {
+		select {
+		case <-ch: // drop the frame not yet taken
+		default:
+		}
+		ch <- m.last
+	}
+}
+
+// Watch returns the frames as they are flushed, starting with the one
+// showing now. stop ends watching.
+func (m *Mirror) Watch() (frames <-chan Frame, stop func()) {
+	ch := make(chan Frame, 1)
+	m.mu.Lock()
+	m.watchers[ch] = struct{}{}
+	ch <- m.last
+	m.mu.Unlock()
+	return ch, func() {
+		m.mu.Lock()
+		delete(m.watchers, ch)
+		m.mu.Unlock()
+	}
+}
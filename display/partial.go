@@ -0,0 +1,195 @@
+package display
+
+import "tinygo.org/x/drivers"
+
+// SSD1306 commands for addressing a window of the display RAM
+const (
+	columnAddr = 0x21
+	pageAddr   = 0x22
+)
+
+// Link is the connection to an SSD1306 controller underneath the driver:
+// raw commands and raw display RAM data
+type Link interface {
+	// Commands sends one or more command bytes (with their parameters)
+	Commands(cmds ...byte) error
+	// Data writes bytes to display RAM at the controller's pointer
+	Data(data []byte) error
+}
+
+// I2CLink is a Link over I2C
+type I2CLink struct {
+	bus     drivers.I2C
+	address uint16
+	buf     []byte // control byte and payload, reused between writes
+}
+
+// NewI2CLink creates a link to the controller at address (usually 0x3C)
+func NewI2CLink(bus drivers.I2C, address uint16) *I2CLink {
+	return &I2CLink{bus: bus, address: address}
+}
+
+// Commands sends cmds in one transaction: a 0x00 control byte and then
+// the command stream
+func (l *I2CLink) Commands(cmds ...byte) error {
+	l.buf = append(append(l.buf[:0], 0x00), cmds...)
+	return l.bus.Tx(l.address, l.buf, nil)
+}
+
+// Data sends data in one transaction after a 0x40 control byte
+func (l *I2CLink) Data(data []byte) error {
+	l.buf = append(append(l.buf[:0], 0x40), data...)
+	return l.bus.Tx(l.address, l.buf, nil)
+}
+
+// Partial is a Display for an SSD1306-family controller that only sends
+// what changed. It
+// keeps a copy of what the panel shows, and on Display compares each page
+// (8 pixel rows) with it and sends just the columns from the first to the
+// last changed byte. A Game of Life generation usually changes a fraction
+// of the screen, and an unchanged frame sends nothing at all.
+//
+// The controller must already be set up, by Open or by the driver's
+// Configure; horizontal addressing is used unless the Controller only has
+// page addressing.
+type Partial struct {
+	*Framebuffer
+	link    Link
+	ctrl    Controller
+	shown   []byte // what the panel has in RAM
+	stale   bool   // shown can't be trusted; send everything
+	spans   []span // changed columns of each page, found by Display
+	sendBuf []byte // one window's data
+	stats   FlushStats
+}
+
+// FlushStats counts what Partial has sent
+type FlushStats struct {
+	Flushes int // calls to Display
+	Windows int // page windows sent
+	Bytes   int // display RAM bytes sent
+}
+
+// NewPartial creates a partial-update display for controller c on link.
+// The first Display sends the whole buffer.
+func NewPartial(link Link, c Controller) *Partial {
+	fb := NewFramebuffer(c.Width, c.Height)
+	return &Partial{
+		Framebuffer: fb,
+		link:        link,
+		ctrl:        c,
+		shown:       make([]byte, len(fb.buffer)),
+		stale:       true,
+		spans:       make([]span, (int(c.Height)+7)/8),
+	}
+}
+
+// windowCost is what a window costs on the bus beyond its data: the
+// addressing commands and the control bytes
+const windowCost = 8
+
+// Display sends the changed part of every page to the panel. When so much
+// has changed that the windows would cost more than the whole screen, it
+// sends the whole screen as one window instead.
+func (p *Partial) Display() error {
+	p.stats.Flushes++
+	width := int(p.width)
+
+	// Find the changed columns of each page
+	cost := 0
+	for page := range p.spans {
+		row := p.buffer[page*width : (page+1)*width]
+		shown := p.shown[page*width : (page+1)*width]
+		first, last := 0, width-1
+		for first < width && row[first] == shown[first] {
+			first++
+		}
+		if first == width {
+			p.spans[page] = span{-1, -1} // unchanged
+			continue
+		}
+		for row[last] == shown[last] {
+			last--
+		}
+		p.spans[page] = span{first, last}
+		cost += windowCost + last + 1 - first
+	}
+
+	if p.stale || cost > windowCost+len(p.buffer) {
+		return p.send(0, width-1, 0, len(p.spans)-1)
+	}
+	for page, sp := range p.spans {
+		if sp.first < 0 {
+			continue
+		}
+		if err := p.send(sp.first, sp.last, page, page); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// span is the changed columns of a page, first to last inclusive
+type span struct {
+	first, last int
+}
+
+// send writes columns first-last of pages from-to to the panel
+func (p *Partial) send(first, last, from, to int) error {
+	width := int(p.width)
+	col := first + p.ctrl.ColumnOffset
+	if p.ctrl.PageAddressing {
+		// One page at a time, each with its own address
+		for page := from; page <= to; page++ {
+			cmds := []byte{pageStart | byte(page), columnLow | byte(col&0x0F), columnHigh | byte(col>>4)}
+			if err := p.write(cmds, p.buffer[page*width+first:page*width+last+1]); err != nil {
+				return err
+			}
+		}
+	} else {
+		cmds := []byte{columnAddr, byte(col), byte(last + p.ctrl.ColumnOffset), pageAddr, byte(from), byte(to)}
+		p.sendBuf = p.sendBuf[:0]
+		for page := from; page <= to; page++ {
+			p.sendBuf = append(p.sendBuf, p.buffer[page*width+first:page*width+last+1]...)
+		}
+		if err := p.write(cmds, p.sendBuf); err != nil {
+			return err
+		}
+	}
+	for page := from; page <= to; page++ {
+		copy(p.shown[page*width+first:page*width+last+1], p.buffer[page*width+first:page*width+last+1])
+	}
+	p.stale = false
+	return nil
+}
+
+// write sends the addressing commands and then the data for one window
+func (p *Partial) write(cmds, data []byte) error {
+	err := p.link.Commands(cmds...)
+	if err == nil {
+		err = p.link.Data(data)
+	}
+	if err != nil {
+		p.stale = true // the panel may be half written
+		return err
+	}
+	p.stats.Windows++
+	p.stats.Bytes += len(data)
+	return nil
+}
+
+// Invalidate makes the next Display send the whole buffer, for when the
+// panel's RAM was changed behind Partial's back (by the driver, say)
+func (p *Partial) Invalidate() {
+	p.stale = true
+}
+
+// Command sends a single command, so SetContrast works on a Partial
+func (p *Partial) Command(cmd uint8) {
+	p.link.Commands(cmd)
+}
+
+// Stats returns what has been sent so far
+func (p *Partial) Stats() FlushStats {
+	return p.stats
+}
@@ -0,0 +1,49 @@
+//go:build tinygo
+
+package display
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers"
+)
+
+// SPILink is a Link over 4-wire SPI: D/C low for commands, high for data
+type SPILink struct {
+	bus    drivers.SPI
+	dc, cs machine.Pin
+}
+
+// NewSPILink configures the control pins and resets the controller.
+// reset may be machine.NoPin on modules that tie it high.
+func NewSPILink(bus drivers.SPI, dc, cs, reset machine.Pin) *SPILink {
+	dc.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	cs.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	cs.High()
+	if reset != machine.NoPin {
+		reset.Configure(machine.PinConfig{Mode: machine.PinOutput})
+		reset.High()
+		time.Sleep(time.Millisecond)
+		reset.Low()
+		time.Sleep(10 * time.Millisecond)
+		reset.High()
+	}
+	return &SPILink{bus: bus, dc: dc, cs: cs}
+}
+
+func (l *SPILink) Commands(cmds ...byte) error {
+	return l.tx(cmds, false)
+}
+
+func (l *SPILink) Data(data []byte) error {
+	return l.tx(data, true)
+}
+
+func (l *SPILink) tx(b []byte, data bool) error {
+	l.dc.Set(data)
+	l.cs.Low()
+	err := l.bus.Tx(b, nil)
+	l.cs.High()
+	return err
+}
@@ -0,0 +1,53 @@
+package display
+
+import "tinygo.org/x/drivers"
+
+// MuxAddress is the TCA9548A's address with A0-A2 tied low
+const MuxAddress = 0x70
+
+// TCA9548A is an 8-channel I2C multiplexer. SSD1306 modules only come at
+// 0x3C or 0x3D, so more than two on one bus have to sit on separate
+// channels of a multiplexer.
+type TCA9548A struct {
+	bus      drivers.I2C
+	address  uint16
+	selected int // channel switched in, -1 when unknown
+}
+
+// NewTCA9548A creates a multiplexer at address on bus
+func NewTCA9548A(bus drivers.I2C, address uint16) *TCA9548A {
+	return &TCA9548A{bus: bus, address: address, selected: -1}
+}
+
+// Channel returns one of the multiplexer's channels (0-7) as an I2C bus.
+// Each transaction on it first switches the multiplexer to the channel,
+// if it isn't already.
+func (m *TCA9548A) Channel(n int) drivers.I2C {
+	return &muxChannel{mux: m, channel: n & 7}
+}
+
+// selectChannel switches exactly channel n in
+func (m *TCA9548A) selectChannel(n int) error {
+	if m.selected == n {
+		return nil
+	}
+	if err := m.bus.Tx(m.address, []byte{1 << n}, nil); err != nil {
+		m.selected = -1
+		return err
+	}
+	m.selected = n
+	return nil
+}
+
+// muxChannel is a channel of a TCA9548A
+type muxChannel struct {
+	mux     *TCA9548A
+	channel int
+}
+
+func (c *muxChannel) Tx(addr uint16, w, r []byte) error {
+	if err := c.mux.selectChannel(c.channel); err != nil {
+		return err
+	}
+	return c.mux.bus.Tx(addr, w, r)
+}
@@ -0,0 +1,90 @@
+package display
+
+import (
+	"errors"
+	"image/color"
+)
+
+// Tiled is one big Display made of several panels of the same size, laid
+// out in rows left to right and top to bottom. Drawing at x=130 on two
+// 128x64 panels side by side lights the third column of the second panel,
+// so a game sees one 256x64 screen.
+type Tiled struct {
+	panels        []Display
+	columns       int
+	width, height int16 // of one panel
+}
+
+// NewTiled puts panels together, columns of them to a row. They must all
+// be the size of the first.
+func NewTiled(columns int, panels ...Display) (*Tiled, error) {
+	if len(panels) == 0 {
+		return nil, errors.New("display: no panels to tile")
+	}
+	if columns < 1 || columns > len(panels) {
+		columns = len(panels)
+	}
+	w, h := panels[0].Size()
+	for _, p := range panels[1:] {
+		if pw, ph := p.Size(); pw != w || ph != h {
+			return nil, errors.New("display: tiled panels differ in size")
+		}
+	}
+	return &Tiled{panels: panels, columns: columns, width: w, height: h}, nil
+}
+
+// Size returns the size of all the panels together
+func (t *Tiled) Size() (x, y int16) {
+	rows := (len(t.panels) + t.columns - 1) / t.columns
+	return t.width * int16(t.columns), t.height * int16(rows)
+}
+
+// SetPixel sets the pixel on whichever panel (x, y) falls on
+func (t *Tiled) SetPixel(x, y int16, c color.RGBA) {
+	if x < 0 || y < 0 {
+		return
+	}
+	col, row := int(x/t.width), int(y/t.height)
+	if col >= t.columns {
+		return
+	}
+	i := row*t.columns + col
+	if i >= len(t.panels) {
+		return
+	}
+	t.panels[i].SetPixel(x%t.width, y%t.height, c)
+}
+
+// ClearBuffer clears every panel's buffer
+func (t *Tiled) ClearBuffer() {
+	for _, p := range t.panels {
+		p.ClearBuffer()
+	}
+}
+
+// Display flushes every panel. A panel that fails doesn't stop the others
+// from being updated; the first error is returned.
+func (t *Tiled) Display() error {
+	var first error
+	for _, p := range t.panels {
+		if err := p.Display(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Command sends a command to every panel that takes them, so SetContrast
+// dims all of them
+func (t *Tiled) Command(cmd uint8) {
+	for _, p := range t.panels {
+		if c, ok := p.(Commander); ok {
+			c.Command(cmd)
+		}
+	}
+}
+
+// Panels returns the panels, in the order they were tiled
+func (t *Tiled) Panels() []Display {
+	return t.panels
+}
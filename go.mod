@@ -3,6 +3,8 @@ module gameoflife
 go 1.25.5
 
 require (
-	github.com/google/shlex v0.0.0-20191202100458-e7afc7fbc510 // indirect
-	tinygo.org/x/drivers v0.34.0 // indirect
+	tinygo.org/x/drivers v0.34.0
+	tinygo.org/x/tinyfont v0.3.0
 )
+
+require github.com/google/shlex v0.0.0-20191202100458-e7afc7fbc510 // indirect
@@ -4,7 +4,14 @@ package main
 import (
 	"fmt"
 	"math/rand"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
 	"time"
+
+	"gameoflife/rle"
+	"gameoflife/terminal"
 )
 
 const (
@@ -220,6 +227,30 @@ func (g *Grid) DisplayCompact() {
 	}
 }
 
+// Frame renders the part of the grid seen through the viewport with the
+// given renderer and colours, below the same title as DisplayCompact,
+// one line per screen row
+func (g *Grid) Frame(v *terminal.Viewport, r terminal.Renderer, h *terminal.History, p terminal.Palette) [][]terminal.Cell {
+	v.Board = g
+	title := fmt.Sprintf("Conway's Game of Life - 128x64 Grid (%s, %s) view %d,%d zoom %dx",
+		r.Name, p.Theme.Name, v.X, v.Y, v.Zoom())
+	if v.Zoom() < 0 {
+		title = fmt.Sprintf("Conway's Game of Life - 128x64 Grid (%s, %s) view %d,%d zoom 1/%d",
+			r.Name, p.Theme.Name, v.X, v.Y, -v.Zoom())
+	}
+	lines := [][]terminal.Cell{
+		terminal.Text(title),
+		terminal.Text("===================================="),
+	}
+	return append(lines, r.RenderCells(v, v.Ages(h), p)...)
+}
+
+// Screen rows used around the board by the game loop
+const (
+	headerRows = 2 // title lines added by Frame
+	statusRows = 5 // generation, two help lines and a message
+)
+
 // CountLiveCells returns the number of live cells
 func (g *Grid) CountLiveCells() int {
 	count := 0
@@ -233,50 +264,307 @@ func (g *Grid) CountLiveCells() int {
 	return count
 }
 
-func main() {
-	fmt.Println("Conway's Game of Life - Go Implementation")
-	fmt.Println("=========================================")
-	fmt.Println("\nChoose a starting pattern:")
-	fmt.Println("1. Random")
-	fmt.Println("2. Glider")
-	fmt.Println("3. Blinker")
-	fmt.Println("4. Toad")
-	fmt.Println("5. Pulsar")
-	fmt.Print("\nEnter choice (1-5): ")
-
-	var choice int
-	fmt.Scanln(&choice)
+// Size returns the grid dimensions
+func (g *Grid) Size() (int, int) {
+	return Width, Height
+}
 
+// Alive reports whether the cell at (x, y) is alive
+func (g *Grid) Alive(x, y int) bool {
+	if x < 0 || x >= Width || y < 0 || y >= Height {
+		return false
+	}
+	return g.cells[y][x]
+}
+
+// Set makes the cell at (x, y) alive or dead
+func (g *Grid) Set(x, y int, alive bool) {
+	if x < 0 || x >= Width || y < 0 || y >= Height {
+		return
+	}
+	g.cells[y][x] = alive
+}
+
+// SavePattern writes the grid's live cells to an RLE pattern file
+func (g *Grid) SavePattern(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if err := rle.Encode(f, g, name); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// LoadPattern creates a grid from an RLE pattern file, centred on the board
+func LoadPattern(path string) (*Grid, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	p, err := rle.Decode(f)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &Grid{}
+	ox, oy := (Width-p.Width)/2, (Height-p.Height)/2
+	for y := 0; y < p.Height; y++ {
+		for x := 0; x < p.Width; x++ {
+			if p.Alive(x, y) {
+				g.Set(ox+x, oy+y, true)
+			}
+		}
+	}
+	return g, nil
+}
+
+func main() {
 	var grid *Grid
-	switch choice {
-	case 2:
-		grid = NewGridWithPattern("glider")
-	case 3:
-		grid = NewGridWithPattern("blinker")
-	case 4:
-		grid = NewGridWithPattern("toad")
-	case 5:
-		grid = NewGridWithPattern("pulsar")
-	default:
-		grid = NewGrid()
+	paused := false
+
+	if len(os.Args) > 1 {
+		// go run gpt_version1.go my_seed.rle
+		g, err := LoadPattern(os.Args[1])
+		if err != nil {
+			fmt.Println("Could not load pattern:", err)
+			os.Exit(1)
+		}
+		grid = g
+		paused = true
+	} else {
+		fmt.Println("Conway's Game of Life - Go Implementation")
+		fmt.Println("=========================================")
+		fmt.Println("\nChoose a starting pattern:")
+		fmt.Println("1. Random")
+		fmt.Println("2. Glider")
+		fmt.Println("3. Blinker")
+		fmt.Println("4. Toad")
+		fmt.Println("5. Pulsar")
+		fmt.Println("6. Blank (draw your own)")
+		fmt.Print("\nEnter choice (1-6): ")
+
+		var choice int
+		fmt.Scanln(&choice)
+
+		switch choice {
+		case 2:
+			grid = NewGridWithPattern("glider")
+		case 3:
+			grid = NewGridWithPattern("blinker")
+		case 4:
+			grid = NewGridWithPattern("toad")
+		case 5:
+			grid = NewGridWithPattern("pulsar")
+		case 6:
+			grid = &Grid{}
+			paused = true
+		default:
+			grid = NewGrid()
+		}
 	}
 
-	fmt.Println("\nStarting simulation... Press Ctrl+C to stop.")
+	fmt.Println("\nStarting simulation... Press q or Ctrl+C to stop.")
 	time.Sleep(2 * time.Second)
 
+	// Read single key presses and mouse clicks from the terminal
+	restore, err := terminal.MakeRaw()
+	if err != nil {
+		fmt.Println("Keyboard/mouse input unavailable:", err)
+		restore = func() {}
+	}
+	terminal.EnableMouse(os.Stdout)
+
+	// Only the characters that changed are redrawn each frame
+	screen := terminal.NewScreen(os.Stdout)
+	cleanup := func() {
+		screen.Close()
+		terminal.DisableMouse(os.Stdout)
+		restore()
+	}
+	defer cleanup()
+
+	// Ctrl+C must still put the terminal back the way we found it. It is
+	// handled in the game loop, so cleaning up can't race a frame being
+	// drawn.
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+
+	events := make(chan terminal.Event, 64)
+	go terminal.ReadEvents(os.Stdin, events)
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	// The viewport shows as much of the grid as fits; arrow keys pan it and
+	// +/- zoom. Unless a renderer was picked with 'r', the most detailed
+	// one that fits is chosen again whenever the window or zoom changes.
+	// While paused for drawing, the view zooms in until every cell covers
+	// at least a whole character: a click only says which character it
+	// hit, so the other cells packed into one couldn't be reached.
+	view := terminal.NewViewport(grid)
+	renderer := terminal.Full
+	renderers := []terminal.Renderer{terminal.Full, terminal.HalfBlock, terminal.Braille, terminal.Compact}
+	autoRenderer := true
+	fitToScreen := func() {
+		cols, rows := terminal.Size()
+		rows -= headerRows + statusRows
+		for {
+			if autoRenderer {
+				w, h := view.ZoomedSize()
+				renderer = terminal.Choose(cols, rows, w, h)
+			}
+			zoom := view.Zoom()
+			if !paused || zoom >= max(renderer.ScaleX, renderer.ScaleY) {
+				break
+			}
+			if view.ZoomIn(); view.Zoom() == zoom {
+				break // as far in as it goes
+			}
+		}
+		view.Resize(cols*renderer.ScaleX, max(rows, 1)*renderer.ScaleY)
+		screen.Invalidate()
+	}
+	fitToScreen()
+
+	resized := make(chan os.Signal, 1)
+	terminal.NotifyResize(resized)
+
+	// Colour cells by age unless NO_COLOR is set or the output is redirected.
+	// GOL_THEME picks the starting theme.
+	palette := terminal.Palette{Mode: terminal.DetectColorMode(os.Stdout)}
+	palette.Theme = terminal.ThemeByName("heat")
+	if name := os.Getenv("GOL_THEME"); name != "" {
+		palette.Theme = terminal.ThemeByName(name)
+	}
+	history := &terminal.History{}
+
 	generation := 0
+	paint := true // state dragged cells are set to
+	message := ""
 
 	// Run the game loop
 	for {
 		// Display the current generation
-		grid.DisplayCompact()
-		fmt.Printf("\nGeneration: %d | Live Cells: %d\n", generation, grid.CountLiveCells())
+		lines := grid.Frame(view, renderer, history, palette)
+		status := fmt.Sprintf("Generation: %d | Live Cells: %d | %d bytes/frame",
+			generation, grid.CountLiveCells(), screen.FrameBytes())
+		help := "space=pause  s=save  r=renderer  t=theme  q=quit"
+		if paused {
+			help = "[PAUSED] click/drag=draw  space=run  n=step  c=clear  s=save  r=renderer  t=theme  q=quit"
+		}
+		viewHelp := "arrows/hjkl=pan  +/-=zoom  0=reset view"
+		lines = append(lines, nil, terminal.Text(status), terminal.Text(help), terminal.Text(viewHelp), terminal.Text(message))
+		screen.Draw(lines)
+
+		// Pan by an eighth of the view
+		viewW, viewH := view.Size()
+		stepX, stepY := max(viewW/8, 1), max(viewH/8, 1)
+
+		select {
+		case <-interrupt:
+			return
+
+		case <-resized:
+			fitToScreen()
+
+		case ev := <-events:
+			switch ev.Kind {
+			case terminal.KeyPress:
+				message = ""
+				switch ev.Key {
+				case ' ':
+					paused = !paused
+					fitToScreen()
+				case 'n':
+					if paused {
+						grid = grid.Next()
+						history.Update(grid)
+						generation++
+					}
+				case 'c':
+					grid = &Grid{}
+					history.Reset()
+					generation = 0
+					paused = true
+					fitToScreen()
+				case 's':
+					path := time.Now().Format("pattern_20060102_150405.rle")
+					if err := grid.SavePattern(path); err != nil {
+						message = "Save failed: " + err.Error()
+					} else {
+						message = "Saved " + path
+					}
+				case 'r':
+					// Cycle through the renderers
+					for i, r := range renderers {
+						if r.Name == renderer.Name {
+							renderer = renderers[(i+1)%len(renderers)]
+							break
+						}
+					}
+					autoRenderer = false
+					fitToScreen()
+				case terminal.KeyLeft, 'h':
+					view.Pan(-stepX, 0)
+				case terminal.KeyRight, 'l':
+					view.Pan(stepX, 0)
+				case terminal.KeyUp, 'k':
+					view.Pan(0, -stepY)
+				case terminal.KeyDown, 'j':
+					view.Pan(0, stepY)
+				case '+', '=':
+					view.ZoomIn()
+					fitToScreen()
+				case '-':
+					view.ZoomOut()
+					fitToScreen()
+				case '0':
+					view.Reset()
+					autoRenderer = true
+					fitToScreen()
+				case 't':
+					// Cycle through the colour themes
+					for i, t := range terminal.Themes {
+						if t.Name == palette.Theme.Name {
+							palette.Theme = terminal.Themes[(i+1)%len(terminal.Themes)]
+							break
+						}
+					}
+				case 'q':
+					return
+				}
 
-		// Compute next generation
-		grid = grid.Next()
-		generation++
+			case terminal.MousePress, terminal.MouseDrag:
+				// Cells can only be edited while the simulation is paused
+				if !paused || ev.Button != terminal.ButtonLeft {
+					continue
+				}
+				vx, vy, ok := renderer.Layout(view, headerRows, 0).Cell(ev.Col, ev.Row)
+				if !ok {
+					continue
+				}
+				x, y := view.ToBoard(vx, vy)
+				if ev.Kind == terminal.MousePress {
+					// A click toggles the cell; dragging paints that same state
+					paint = !grid.Alive(x, y)
+				}
+				grid.Set(x, y, paint)
+			}
 
-		// Wait before next frame
-		time.Sleep(100 * time.Millisecond)
+		case <-ticker.C:
+			if paused {
+				continue
+			}
+			// Compute next generation
+			grid = grid.Next()
+			history.Update(grid)
+			generation++
+		}
 	}
 }
@@ -0,0 +1,96 @@
+package input
+
+import "sync/atomic"
+
+// Encoder is a rotary encoder
+type Encoder interface {
+	// Turns returns how many detents it has been turned since the last
+	// call: positive clockwise, negative anticlockwise
+	Turns() int
+}
+
+// Quadrature decodes the two-phase signal from an encoder's A (CLK) and
+// B (DT) pins. Feed it every change of either pin with Update; it counts
+// valid transitions and ignores invalid ones, which is what contact bounce
+// produces, so it needs no separate debouncing.
+//
+// Update may be called from an interrupt while Turns is called from the
+// main loop.
+type Quadrature struct {
+	// StepsPerDetent is how many transitions make one click of the knob;
+	// 4 for a KY-040 (a full cycle per detent)
+	StepsPerDetent int32
+
+	state int32 // last AB reading, 2 bits
+	steps int32 // transitions not yet turned into detents
+}
+
+// quadratureTable gives the direction of a transition indexed by
+// old state << 2 | new state: +1, -1, or 0 for no change or an invalid
+// jump (both pins changed at once)
+var quadratureTable = [16]int32{
+	0, -1, 1, 0,
+	1, 0, 0, -1,
+	-1, 0, 0, 1,
+	0, 1, -1, 0,
+}
+
+// NewQuadrature creates a decoder for an encoder whose pins currently
+// read a and b
+func NewQuadrature(a, b bool) *Quadrature {
+	return &Quadrature{StepsPerDetent: 4, state: ab(a, b)}
+}
+
+// Update records the current pin levels
+func (q *Quadrature) Update(a, b bool) {
+	state := ab(a, b)
+	old := atomic.SwapInt32(&q.state, state)
+	if dir := quadratureTable[old<<2|state]; dir != 0 {
+		atomic.AddInt32(&q.steps, dir)
+	}
+}
+
+// Turns returns the whole detents turned since the last call; part of a
+// detent is kept for next time
+func (q *Quadrature) Turns() int {
+	per := q.StepsPerDetent
+	if per <= 0 {
+		per = 1
+	}
+	for {
+		steps := atomic.LoadInt32(&q.steps)
+		detents := steps / per
+		if detents == 0 {
+			return 0
+		}
+		if atomic.CompareAndSwapInt32(&q.steps, steps, steps-detents*per) {
+			return int(detents)
+		}
+	}
+}
+
+func ab(a, b bool) int32 {
+	var s int32
+	if a {
+		s |= 2
+	}
+	if b {
+		s |= 1
+	}
+	return s
+}
+
+// KeyEncoder is an encoder turned from the desktop keyboard
+type KeyEncoder struct {
+	turns atomic.Int32
+}
+
+// Turn turns the knob n detents (negative for anticlockwise)
+func (k *KeyEncoder) Turn(n int) {
+	k.turns.Add(int32(n))
+}
+
+// Turns returns the detents turned since the last call
+func (k *KeyEncoder) Turns() int {
+	return int(k.turns.Swap(0))
+}
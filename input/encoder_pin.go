@@ -0,0 +1,30 @@
+//go:build tinygo
+
+package input
+
+import "machine"
+
+// PinEncoder is a KY-040 style rotary encoder on two GPIO pins, decoded
+// from pin-change interrupts so no detent is missed between frames.
+// The module has its own pull-ups on CLK and DT; the internal ones are
+// enabled too so a bare encoder works.
+type PinEncoder struct {
+	*Quadrature
+	clk, dt machine.Pin
+}
+
+// NewPinEncoder configures clk (A) and dt (B) and starts decoding
+func NewPinEncoder(clk, dt machine.Pin) (*PinEncoder, error) {
+	clk.Configure(machine.PinConfig{Mode: machine.PinInputPullup})
+	dt.Configure(machine.PinConfig{Mode: machine.PinInputPullup})
+
+	e := &PinEncoder{Quadrature: NewQuadrature(clk.Get(), dt.Get()), clk: clk, dt: dt}
+	update := func(machine.Pin) { e.Update(e.clk.Get(), e.dt.Get()) }
+	if err := clk.SetInterrupt(machine.PinToggle, update); err != nil {
+		return nil, err
+	}
+	if err := dt.SetInterrupt(machine.PinToggle, update); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
@@ -0,0 +1,37 @@
+// Package input is where the firmware gets its button presses from.
+//
+// The game loops only see the Button interface, so the same code can read
+// a GPIO pin on the ESP32 (Pin), the keyboard in the desktop simulator
+// (Key) or a scripted timeline in a test (Script).
+package input
+
+import "time"
+
+// Button is a push button that can be polled
+type Button interface {
+	// Pressed reports whether the button is held down right now
+	Pressed() bool
+}
+
+// ButtonFunc adapts a plain function to a Button
+type ButtonFunc func() bool
+
+// Pressed calls f
+func (f ButtonFunc) Pressed() bool {
+	return f()
+}
+
+// Clock tells the time. Anything that measures how long a button was held
+// takes one, so a Script can run it on simulated time.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the real clock
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
@@ -0,0 +1,43 @@
+package input
+
+import (
+	"sync"
+	"time"
+)
+
+// KeyHold is how long a key press keeps a Key down. Terminals only report
+// key presses, not releases, so a held key is seen through auto-repeat:
+// each repeat extends the hold. It is long enough to bridge the gap
+// between repeats but short enough that two quick taps still read as a
+// double click.
+const KeyHold = 120 * time.Millisecond
+
+// Key is a button driven from the desktop keyboard or mouse. It is safe to
+// press from one goroutine while the firmware polls it from another.
+type Key struct {
+	mu        sync.Mutex
+	heldUntil time.Time // set by key presses
+	held      bool      // set by mouse press/release, which are exact
+}
+
+// Tap presses the button for KeyHold, or extends a press in progress
+func (k *Key) Tap() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.heldUntil = time.Now().Add(KeyHold)
+}
+
+// SetHeld holds the button down until SetHeld(false), for input that
+// reports releases (such as a mouse button)
+func (k *Key) SetHeld(held bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.held = held
+}
+
+// Pressed reports whether the key is being held
+func (k *Key) Pressed() bool {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.held || time.Now().Before(k.heldUntil)
+}
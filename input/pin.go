@@ -0,0 +1,22 @@
+//go:build tinygo
+
+package input
+
+import "machine"
+
+// Pin is a button wired between a GPIO pin and ground, read through the
+// pin's internal pull-up (so pressed reads low)
+type Pin struct {
+	pin machine.Pin
+}
+
+// NewPin configures pin as a pulled-up input
+func NewPin(pin machine.Pin) Pin {
+	pin.Configure(machine.PinConfig{Mode: machine.PinInputPullup})
+	return Pin{pin: pin}
+}
+
+// Pressed reports whether the button is pulling the pin low
+func (p Pin) Pressed() bool {
+	return !p.pin.Get()
+}
@@ -0,0 +1,116 @@
+package input
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Script is a button that follows a timeline of presses and releases, on a
+// simulated clock. It is both the Button and the Clock for whatever reads
+// it, so timing-dependent code runs exactly the same every time:
+//
+//	s := input.NewScript().Press(0).Release(80 * time.Millisecond).
+//		Press(200 * time.Millisecond).Release(260 * time.Millisecond)
+//	d := ui.NewClickDetectorClock(s)
+//	for s.Elapsed() < s.End()+time.Second {
+//		_, double := d.CheckClick(s.Pressed())
+//		...
+//		s.Advance(10 * time.Millisecond)
+//	}
+type Script struct {
+	start   time.Time
+	elapsed time.Duration
+	edges   []edge
+}
+
+// edge is the button changing state at a point in the script
+type edge struct {
+	at   time.Duration
+	down bool
+}
+
+// NewScript creates a script with the button up and the clock at 0
+func NewScript() *Script {
+	// Any fixed instant works; it only has to be the same every run
+	return &Script{start: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+// Press puts the button down at time at
+func (s *Script) Press(at time.Duration) *Script {
+	return s.add(at, true)
+}
+
+// Release lets the button up at time at
+func (s *Script) Release(at time.Duration) *Script {
+	return s.add(at, false)
+}
+
+// Click presses at time at and releases after held
+func (s *Script) Click(at, held time.Duration) *Script {
+	return s.Press(at).Release(at + held)
+}
+
+func (s *Script) add(at time.Duration, down bool) *Script {
+	s.edges = append(s.edges, edge{at, down})
+	sort.SliceStable(s.edges, func(i, j int) bool {
+		return s.edges[i].at < s.edges[j].at
+	})
+	return s
+}
+
+// Pressed reports the button state at the current time
+func (s *Script) Pressed() bool {
+	down := false
+	for _, e := range s.edges {
+		if e.at > s.elapsed {
+			break
+		}
+		down = e.down
+	}
+	return down
+}
+
+// Now returns the simulated time
+func (s *Script) Now() time.Time {
+	return s.start.Add(s.elapsed)
+}
+
+// Advance moves the clock forward
+func (s *Script) Advance(d time.Duration) {
+	s.elapsed += d
+}
+
+// Sleep is Advance, for code that sleeps on the clock it is given
+func (s *Script) Sleep(d time.Duration) {
+	s.Advance(d)
+}
+
+// Elapsed returns how far the clock has run
+func (s *Script) Elapsed() time.Duration {
+	return s.elapsed
+}
+
+// End returns the time of the last press or release
+func (s *Script) End() time.Duration {
+	if len(s.edges) == 0 {
+		return 0
+	}
+	return s.edges[len(s.edges)-1].at
+}
+
+// String lists the timeline, e.g. "press@0s release@80ms"
+func (s *Script) String() string {
+	str := ""
+	for i, e := range s.edges {
+		if i > 0 {
+			str += " "
+		}
+		action := "release"
+		if e.down {
+			action = "press"
+		}
+		str += fmt.Sprintf("%s@%v", action, e.at)
+	}
+	return str
+}
@@ -0,0 +1,414 @@
+package life
+
+import (
+	"io"
+	"strconv"
+	"time"
+
+	"gameoflife/console"
+	"gameoflife/display"
+	"gameoflife/log"
+	"gameoflife/pacing"
+	"gameoflife/power"
+	"gameoflife/rle"
+	"gameoflife/settings"
+	"gameoflife/telemetry"
+	"gameoflife/ui"
+)
+
+// patterns are the menu's patterns - visually striking ones!
+var patterns = []string{
+	"RANDOM",
+	"DENSE CHAOS",
+	"EXPLOSION",
+	"FIREWORKS",
+	"TRAFFIC LIGHTS",
+	"GLIDER GUN",
+	"SPACESHIP FLEET",
+	"ACORN",
+	"PULSAR",
+	"SPACESHIP",
+	"GLIDER",
+	"TOAD",
+}
+
+// patternKeys name the patterns for Place and the console
+var patternKeys = []string{
+	"random",
+	"dense_chaos",
+	"explosion",
+	"fireworks",
+	"traffic_lights",
+	"gosper_glider_gun",
+	"spaceship_fleet",
+	"acorn",
+	"pulsar",
+	"lightweight_spaceship",
+	"glider",
+	"toad",
+}
+
+// Run is the OLED firmware's main loop: the menu, then the game, forever,
+// controlled through nav (see ui.Navigator for the events each kind of
+// control produces). The settings, including the last pattern played,
+// are loaded from store and saved back to it as they change.
+//
+// Left alone, the panel dims, turns off and finally the board sleeps, as
+// the power settings say; sleep puts the board into deep sleep (nil where
+// there is none). Input wakes the panel; the press that does is ignored.
+//
+// Commands typed on serial (see commands) control the game too: choosing
+// a pattern, pasting one in RLE, the rule, the speed, pausing and
+// stepping. ports are where they come from: the serial port and, where
+// there is one, the web server's (see package web); nil ones are skipped.
+//
+//	Menu: next/prev = scroll, select = open/choose, back = up a level
+//	Game: next/prev = switch pattern, select = pause/resume, back = menu,
+//	      info = show/hide the HUD (generation, population, pattern, FPS)
+func Run(display display.Display, nav ui.Navigator, store *settings.Store, sleep func(), ports ...io.ReadWriter) {
+	// Settings from the last run, or the defaults
+	config, err := store.Load()
+	if err != nil {
+		initLog.Warn("Using default settings:", err)
+	}
+	if config.Pattern >= len(patterns) {
+		config.Pattern = 0
+	}
+	contrast := config.Contrast
+	config.ApplyDisplay(display)
+
+	// Power saving; settings not saved yet are saved before deep sleep.
+	// Without a sleep (the simulator) the panel stays off until input.
+	saver := power.NewManager(display, power.PolicyFor(config), uint8(config.Contrast))
+	if sleep != nil {
+		saver.Sleep = func() {
+			save(store, config)
+			sleep()
+		}
+	}
+
+	// The universe covers the whole display, all the panels of a tiled one
+	w, h := display.Size()
+	width, height := int(w), int(h)
+
+	// Menus: GAME OF LIFE > PATTERNS (choosing one starts it) / SETTINGS
+	patternMenu := ui.NewListMenu("PATTERNS", patterns)
+	for i := range patternMenu.Items {
+		patternMenu.Items[i].Action = func() { config.Pattern = i }
+		patternMenu.Items[i].Exit = true
+	}
+	patternMenu.Items = append(patternMenu.Items, ui.Item{Label: "< Back", Back: true})
+
+	ruleNames := make([]string, len(Rules))
+	for i, r := range Rules {
+		ruleNames[i] = r.Name
+	}
+	powerMenu := &ui.Menu{
+		Title: "POWER",
+		Items: []ui.Item{
+			{Label: "Dim after", Value: &ui.Slider{Value: &config.DimAfter, Min: 0, Max: 60, Step: 1, Unit: "min", Zero: "Never"}},
+			{Label: "Screen off", Value: &ui.Slider{Value: &config.BlankAfter, Min: 0, Max: 120, Step: 5, Unit: "min", Zero: "Never"}},
+			{Label: "Sleep after", Value: &ui.Slider{Value: &config.SleepAfter, Min: 0, Max: 240, Step: 15, Unit: "min", Zero: "Never"}},
+			{Label: "Pixel shift", Value: &ui.Toggle{Value: &config.PixelShift}},
+			{Label: "< Back", Back: true},
+		},
+	}
+	settingsMenu := &ui.Menu{
+		Title: "SETTINGS",
+		Items: []ui.Item{
+			{Label: "Speed", Value: &ui.Slider{Value: &config.Speed, Min: 50, Max: 1000, Step: 50, Unit: "ms"}},
+			{Label: "Density", Value: &ui.Slider{Value: &config.Density, Min: 5, Max: 95, Step: 5, Unit: "%"}},
+			{Label: "Wrap edges", Value: &ui.Toggle{Value: &config.Wrap}},
+			{Label: "Rule", Value: &ui.Choice{Value: &config.Rule, Options: ruleNames}},
+			{Label: "HUD", Value: &ui.Choice{Value: &config.HUD, Options: ui.HUDModes}},
+			{Label: "Brightness", Value: &ui.Slider{Value: &config.Contrast, Min: 15, Max: 255, Step: 16}},
+			{Label: "Power", Submenu: powerMenu},
+			{Label: "< Back", Back: true},
+		},
+	}
+
+	menu := &ui.Menu{
+		Title: "GAME OF LIFE",
+		Items: []ui.Item{
+			{Label: "Patterns", Submenu: patternMenu},
+			{Label: "Settings", Submenu: settingsMenu},
+		},
+	}
+
+	// Serial console. A loaded pattern is played instead of config.Pattern
+	// until another is chosen; a custom rule stands in for config.Rule.
+	con := console.New(commands, ports...)
+	var loaded *rle.Pattern
+	var custom customRule
+	newGame := func() (*Grid, string) {
+		if loaded != nil {
+			return loadedGame(config, loaded, width, height), loadedName(loaded)
+		}
+		g := NewGame(config, patternKeys[config.Pattern], width, height)
+		custom.apply(g, config)
+		return g, patterns[config.Pattern]
+	}
+
+	initLog.Info("Game of Life Starting...")
+	initLog.Info("Controls: next/prev=scroll, select=choose/pause, back=up/menu, info=HUD")
+
+	// Main loop - alternates between menu and game mode
+	for {
+		// MENU MODE
+		menuLog.Info("Entering menu mode. Selected:", config.Pattern)
+		menu.Reset()
+		patternMenu.SetSelected(config.Pattern)
+		loop := pacing.NewLoop(frameTime, 0) // nothing to simulate
+
+		for selecting := true; selecting; {
+			loop.Begin()
+
+			// Brightness and power settings change as they are edited
+			if config.Contrast != contrast {
+				contrast = config.Contrast
+				saver.SetContrast(uint8(contrast))
+			}
+			saver.Policy = power.PolicyFor(config)
+
+			// Show menu, unless the panel is off
+			if saver.Update() < power.Blanked {
+				menu.Show(display)
+			}
+
+			// Check controls
+			ev := nav.Nav()
+			if ev != ui.NoNav {
+				menuLog.Info(ev.String())
+				if saver.Activity() {
+					ev = ui.NoNav // it only woke the panel
+				}
+			}
+			if menu.Handle(ev) == ui.MenuDone {
+				menuLog.Info("Pattern selected:", patterns[config.Pattern])
+				loaded = nil
+				selecting = false // Exit menu mode
+				continue
+			}
+
+			// Serial commands; choosing a pattern starts it
+			for req, ok := con.Next(); ok; req, ok = con.Next() {
+				saver.Activity()
+				var err error
+				switch req.Name {
+				case "pattern", "load":
+					var p *rle.Pattern
+					var chosen bool
+					if p, chosen, err = choosePattern(req, &config, con, width, height); chosen {
+						loaded = p
+						selecting = false
+					}
+				case "rule", "speed":
+					err = changeSetting(req, &config, &custom)
+				case "settings":
+					printSettings(con, config)
+				case "set":
+					if err = assign(req, &config); err == nil {
+						save(store, config)
+					}
+				default:
+					err = errNotPlaying
+				}
+				con.Done(err)
+			}
+			if !selecting {
+				continue
+			}
+
+			loop.End()
+		}
+
+		// GAME MODE
+		save(store, config)
+		grid, name := newGame()
+		gameLog.Info("Starting pattern:", name)
+		generation := 0
+		paused := false
+		hud := ui.NewHUD(ui.HUDMode(config.HUD))
+		board := boardDisplay(display, config.PixelShift)
+		var changedAt time.Time // settings changed in game, not yet saved
+
+		// Frames are drawn (and the controls read) at a steady rate; a
+		// generation is a step, every Speed ms however long frames take
+		loop = pacing.NewLoop(frameTime, time.Duration(config.Speed)*time.Millisecond)
+		loggedAt, sentAt := time.Now(), time.Now()
+
+		gameRunning := true
+		for gameRunning {
+			steps := loop.Begin()
+
+			// Check controls
+			ev := nav.Nav()
+			if ev != ui.NoNav && saver.Activity() {
+				ev = ui.NoNav // it only woke the panel
+			}
+			switch ev {
+			case ui.NavNext, ui.NavPrev:
+				step := 1
+				if ev == ui.NavPrev {
+					step = len(patterns) - 1
+				}
+				config.Pattern = (config.Pattern + step) % len(patterns)
+				loaded = nil
+				grid, name = newGame()
+				gameLog.Info("Switched to:", name)
+				generation = 0
+				hud.Flash()
+				changedAt = time.Now()
+
+			case ui.NavSelect:
+				paused = !paused
+				gameLog.Info("Paused:", paused)
+
+			case ui.NavBack:
+				gameLog.Info("Returning to menu")
+				save(store, config)
+				gameRunning = false
+
+			case ui.NavInfo:
+				hud.Toggle()
+				config.HUD = int(hud.Mode) // kept for the next game
+				gameLog.Info("HUD:", hud.Mode.String())
+				changedAt = time.Now()
+			}
+
+			// Serial commands
+			for req, ok := con.Next(); ok; req, ok = con.Next() {
+				saver.Activity()
+				var err error
+				switch req.Name {
+				case "pattern", "load":
+					var p *rle.Pattern
+					var chosen bool
+					if p, chosen, err = choosePattern(req, &config, con, width, height); chosen {
+						loaded = p
+						grid, name = newGame()
+						gameLog.Info("Switched to:", name)
+						generation = 0
+						hud.Flash()
+						changedAt = time.Now()
+					}
+				case "rule", "speed":
+					if err = changeSetting(req, &config, &custom); err == nil {
+						if req.Name == "rule" {
+							grid.SetRule(Rules[config.Rule])
+							custom.apply(grid, config)
+						}
+						loop.Step = time.Duration(config.Speed) * time.Millisecond
+						changedAt = time.Now()
+					}
+				case "pause":
+					if paused, err = parsePause(req, paused); err == nil {
+						gameLog.Info("Paused:", paused)
+					}
+				case "step":
+					var n int
+					if n, err = parseSteps(req); err == nil {
+						paused = true
+						for ; n > 0; n-- {
+							grid = grid.Next()
+							generation++
+						}
+						con.Print("generation", generation, "population", grid.CountLiveCells())
+					}
+				case "grid":
+					err = printGrid(con, grid, name)
+				case "settings":
+					printSettings(con, config)
+				case "set":
+					if err = assign(req, &config); err == nil {
+						contrast = config.Contrast
+						saver.SetContrast(uint8(contrast))
+						saver.Policy = power.PolicyFor(config)
+						loop.Step = time.Duration(config.Speed) * time.Millisecond
+						hud.Mode = ui.HUDMode(config.HUD)
+						board = boardDisplay(display, config.PixelShift)
+						save(store, config)
+					}
+				}
+				con.Done(err)
+			}
+
+			// Save once the player has stopped flicking through patterns
+			if !changedAt.IsZero() && time.Since(changedAt) >= saveDelay {
+				save(store, config)
+				changedAt = time.Time{}
+			}
+
+			// Compute the generations due
+			for ; steps > 0 && !paused; steps-- {
+				grid = grid.Next()
+				generation++
+			}
+
+			// Draw current generation, with the HUD over it, unless the
+			// panel is off
+			stats := loop.Stats()
+			if saver.Update() < power.Blanked {
+				grid.Draw(board)
+				hud.Draw(display, name,
+					"G"+strconv.Itoa(generation),
+					"P"+strconv.Itoa(grid.CountLiveCells()),
+					strconv.Itoa(stats.FPS)+"fps",
+					strconv.Itoa(int(stats.FrameTime/time.Millisecond))+"ms")
+				display.Display()
+			}
+
+			if time.Since(loggedAt) >= statsInterval {
+				gameLog.Info("Generation", generation, stats.String())
+				loggedAt = time.Now()
+			}
+			if telemetry.Enabled() && time.Since(sentAt) >= telemetry.Interval {
+				telemetry.Send("life",
+					telemetry.String("pattern", name),
+					telemetry.Int("gen", generation),
+					telemetry.Int("pop", grid.CountLiveCells()),
+					telemetry.Int("fps", stats.FPS),
+					telemetry.Int("ms", int(stats.FrameTime/time.Millisecond)))
+				sentAt = time.Now()
+			}
+
+			// Wait for the next frame
+			loop.End()
+		}
+	}
+}
+
+// Log tags
+var (
+	initLog     = log.New("INIT")
+	menuLog     = log.New("MENU")
+	gameLog     = log.New("GAME")
+	settingsLog = log.New("SETTINGS")
+)
+
+// boardDisplay is what the grid is drawn on: the display, moved around a
+// pixel every few minutes with pixel shift on
+func boardDisplay(d display.Display, pixelShift bool) display.Display {
+	if pixelShift {
+		return power.NewShift(d, power.DefaultShiftInterval)
+	}
+	return d
+}
+
+// frameTime is how often the menu and the game are drawn: 20 frames a
+// second
+const frameTime = 50 * time.Millisecond
+
+// statsInterval is how often the game logs its frame rate
+const statsInterval = 10 * time.Second
+
+// saveDelay is how long in-game changes (pattern, HUD) settle before they
+// are saved, so scrolling through patterns doesn't wear the flash
+const saveDelay = 5 * time.Second
+
+// save stores the settings, logging rather than stopping on failure
+func save(store *settings.Store, config settings.Settings) {
+	if err := store.Save(config); err != nil {
+		settingsLog.Warn("Save failed:", err)
+	}
+}
@@ -0,0 +1,196 @@
+package life
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gameoflife/console"
+	"gameoflife/rle"
+	"gameoflife/settings"
+)
+
+// commands are what the Game of Life answers on the serial console
+var commands = []console.Command{
+	{Name: "pattern", Usage: "[name]", Help: "play a pattern, or list them"},
+	{Name: "load", Body: true, Help: "play an RLE pattern sent after it, up to its '!'"},
+	{Name: "rule", Usage: "<B3/S23>", Help: "change the rule"},
+	{Name: "speed", Usage: "<ms>", Help: "time between generations"},
+	{Name: "pause", Usage: "[on|off]", Help: "pause or resume"},
+	{Name: "step", Usage: "[n]", Help: "pause and run n generations (1)"},
+	{Name: "grid", Help: "print the board as an RLE pattern"},
+	{Name: "settings", Help: "list the settings"},
+	{Name: "set", Usage: "<name>=<value>...", Help: "change and save settings"},
+}
+
+var errNotPlaying = errors.New("life: not playing, in the menu")
+
+// maxSteps caps "step", which runs in one frame
+const maxSteps = 1000
+
+// choosePattern carries out "pattern" and "load": it sets config.Pattern
+// to the named pattern, or returns the pattern loaded, which is played
+// instead. "pattern" alone lists the patterns and chooses none. A loaded
+// pattern is clipped to the width x height grid as it is read.
+func choosePattern(req console.Request, config *settings.Settings, con *console.Console, width, height int) (*rle.Pattern, bool, error) {
+	if req.Name == "load" {
+		p, err := rle.DecodeClip(strings.NewReader(req.Body), width, height)
+		if err != nil {
+			return nil, false, err
+		}
+		w, h := p.Size()
+		con.Print(fmt.Sprintf("%dx%d %s", w, h, p.Rule))
+		return p, true, nil
+	}
+
+	if len(req.Args) == 0 {
+		for i, key := range patternKeys {
+			con.Print(key + strings.Repeat(" ", 24-len(key)) + patterns[i])
+		}
+		return nil, false, nil
+	}
+	i, ok := findPattern(strings.Join(req.Args, " "))
+	if !ok {
+		return nil, false, fmt.Errorf("life: no pattern called %q (try pattern)", strings.Join(req.Args, " "))
+	}
+	config.Pattern = i
+	return nil, true, nil
+}
+
+// findPattern looks a pattern up by key or menu name, in any case and
+// with spaces or underscores
+func findPattern(name string) (int, bool) {
+	name = strings.ReplaceAll(strings.ToLower(name), " ", "_")
+	for i, key := range patternKeys {
+		if key == name || strings.ReplaceAll(strings.ToLower(patterns[i]), " ", "_") == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// loadedGame creates a grid for a loaded pattern, with the wrap setting
+// and the rule in the pattern's header (Conway if it has none)
+func loadedGame(config settings.Settings, p *rle.Pattern, width, height int) *Grid {
+	g := NewSizedGrid(width, height)
+	g.PlaceCells(p)
+	g.SetWrap(config.Wrap)
+	if r, err := ParseRule("", p.Rule); err == nil {
+		g.SetRule(r)
+	}
+	return g
+}
+
+// loadedName is what a loaded pattern is called on the HUD
+func loadedName(p *rle.Pattern) string {
+	if p.Name == "" {
+		return "RLE"
+	}
+	return strings.ToUpper(p.Name)
+}
+
+// customRule is a rule set from the console that isn't one of Rules. It
+// stands in for the Rule setting until that changes.
+type customRule struct {
+	rule    Rule
+	setting int // config.Rule when it was set
+	set     bool
+}
+
+// apply sets the custom rule on g, if it still stands
+func (c *customRule) apply(g *Grid, config settings.Settings) {
+	if c.set && c.setting == config.Rule {
+		g.SetRule(c.rule)
+	}
+}
+
+// changeSetting carries out "rule" and "speed". A built-in rule becomes
+// the Rule setting; any other is kept in custom.
+func changeSetting(req console.Request, config *settings.Settings, custom *customRule) error {
+	switch req.Name {
+	case "rule":
+		r, err := ParseRule("", req.Arg(0))
+		if err != nil {
+			return err
+		}
+		for i, builtin := range Rules {
+			if builtin.String() == r.String() {
+				config.Rule = i
+				custom.set = false
+				return nil
+			}
+		}
+		*custom = customRule{rule: r, setting: config.Rule, set: true}
+		return nil
+
+	case "speed":
+		ms, err := strconv.Atoi(req.Arg(0))
+		if err != nil {
+			return errors.New("life: usage: speed <ms>")
+		}
+		changed := *config
+		changed.Speed = ms
+		if err := changed.Validate(); err != nil {
+			return err
+		}
+		*config = changed
+	}
+	return nil
+}
+
+// parsePause carries out "pause", returning whether the game is paused
+func parsePause(req console.Request, paused bool) (bool, error) {
+	switch strings.ToLower(req.Arg(0)) {
+	case "":
+		return !paused, nil
+	case "on":
+		return true, nil
+	case "off":
+		return false, nil
+	}
+	return paused, errors.New("life: usage: pause [on|off]")
+}
+
+// parseSteps reads the count of "step"
+func parseSteps(req console.Request) (int, error) {
+	if len(req.Args) == 0 {
+		return 1, nil
+	}
+	n, err := strconv.Atoi(req.Arg(0))
+	if err != nil || n < 1 || n > maxSteps {
+		return 0, fmt.Errorf("life: usage: step [n], n from 1 to %d", maxSteps)
+	}
+	return n, nil
+}
+
+// printGrid carries out "grid"
+func printGrid(con *console.Console, grid *Grid, name string) error {
+	var b strings.Builder
+	if err := rle.EncodeRule(&b, grid, strings.ToLower(name), grid.Rule().String()); err != nil {
+		return err
+	}
+	con.Print(strings.TrimSuffix(b.String(), "\n"))
+	return nil
+}
+
+// printSettings carries out "settings"
+func printSettings(con *console.Console, config settings.Settings) {
+	for _, a := range config.Assignments() {
+		con.Print(a)
+	}
+}
+
+// assign carries out "set", also checking the settings that index the
+// patterns, rules and HUD modes
+func assign(req console.Request, config *settings.Settings) error {
+	changed := *config
+	if err := changed.Assign(req.Args); err != nil {
+		return err
+	}
+	if err := CheckSettings(changed); err != nil {
+		return err
+	}
+	*config = changed
+	return nil
+}
@@ -0,0 +1,81 @@
+package life
+
+import (
+	"bytes"
+	"runtime"
+	"testing"
+
+	"gameoflife/console"
+	"gameoflife/settings"
+)
+
+// testPort is a serial port in memory
+type testPort struct {
+	in, out bytes.Buffer
+}
+
+func (p *testPort) Read(b []byte) (int, error)  { return p.in.Read(b) }
+func (p *testPort) Write(b []byte) (int, error) { return p.out.Write(b) }
+
+func TestLoadLargestPattern(t *testing.T) {
+	// As large as a header may say, and as many full rows as the console
+	// takes: decoded whole, these would need megabytes
+	body := "x = 4096, y = 4096\n2000$\n"
+	for len(body)+len("4096o$\n")+len("!\n") <= console.MaxBody {
+		body += "4096o$\n"
+	}
+	body += "!\n"
+
+	port := &testPort{}
+	port.in.WriteString("load\n" + body)
+	con := console.New(commands, port)
+	req, ok := con.Next()
+	if !ok || req.Name != "load" {
+		t.Fatalf("got %+v, %v; want the load request (answer %q)", req, ok, port.out.String())
+	}
+
+	config := settings.Defaults
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	p, chosen, err := choosePattern(req, &config, con, 128, 64)
+	runtime.ReadMemStats(&after)
+	if err != nil || !chosen {
+		t.Fatalf("load failed: %v", err)
+	}
+
+	// Well within the board's heap, whatever the header says
+	if n := after.TotalAlloc - before.TotalAlloc; n > 64<<10 {
+		t.Errorf("decoding took %d bytes", n)
+	}
+	if w, h := p.Size(); w != 128 || h != 64 {
+		t.Fatalf("pattern is %dx%d, want it clipped to 128x64", w, h)
+	}
+	if got := port.out.String(); got != "128x64 B3/S23\n" {
+		t.Errorf("answered %q", got)
+	}
+
+	// The middle of the pattern, which is all alive, fills the grid
+	g := loadedGame(config, p, 128, 64)
+	if n := g.CountLiveCells(); n != 128*64 {
+		t.Errorf("%d cells alive, want %d", n, 128*64)
+	}
+}
+
+func TestLoadClipsToTheMiddle(t *testing.T) {
+	// A 131x3 pattern with a live cell at each end and one in the middle:
+	// the ends don't fit on 128 columns, and are dropped rather than
+	// wrapped around
+	req := console.Request{Name: "load", Body: "x = 131, y = 3\n$o64bo64bo$!\n"}
+	config := settings.Defaults
+	p, _, err := choosePattern(req, &config, console.New(nil), 128, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w, h := p.Size(); w != 128 || h != 3 {
+		t.Errorf("pattern is %dx%d, want 128x3", w, h)
+	}
+	g := loadedGame(config, p, 128, 64)
+	if n := g.CountLiveCells(); n != 1 || !g.Alive(64, 31) {
+		t.Errorf("%d cells alive, want only the middle one at (64, 31)", n)
+	}
+}
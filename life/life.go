@@ -0,0 +1,433 @@
+// Package life is Conway's Game of Life on the 128x64 OLED grid, or on
+// several OLEDs tiled into one bigger grid
+package life
+
+import (
+	"image/color"
+	"math/rand"
+	"time"
+
+	"gameoflife/display"
+	"gameoflife/rle"
+)
+
+// Width and Height are the size of one 128x64 panel, the grid's default
+// size. Tiled displays get a grid as big as all their panels together.
+const (
+	Width  = 128
+	Height = 64
+)
+
+// Grid represents the game board
+type Grid struct {
+	width, height int
+	cells         [][]bool // [y][x]
+	rule          Rule
+	bounded       bool // edges don't wrap: cells past them count as dead
+}
+
+// NewSizedGrid creates an empty width x height grid
+func NewSizedGrid(width, height int) *Grid {
+	cells := make([][]bool, height)
+	all := make([]bool, width*height)
+	for y := range cells {
+		cells[y] = all[y*width : (y+1)*width]
+	}
+	return &Grid{width: width, height: height, cells: cells, rule: Conway}
+}
+
+// NewGrid creates a new grid with random initial state
+func NewGrid() *Grid {
+	// Initialize with random cells (about 30% alive)
+	return NewRandomGrid(30)
+}
+
+// NewRandomGrid creates a grid with about density percent of cells alive
+func NewRandomGrid(density int) *Grid {
+	g := NewSizedGrid(Width, Height)
+	g.Randomize(density)
+	return g
+}
+
+// Randomize brings about density percent of the cells to life
+func (g *Grid) Randomize(density int) {
+	rand.Seed(time.Now().UnixNano())
+
+	for y := 0; y < g.height; y++ {
+		for x := 0; x < g.width; x++ {
+			g.cells[y][x] = rand.Intn(100) < density
+		}
+	}
+}
+
+// Size returns the grid size in cells
+func (g *Grid) Size() (width, height int) {
+	return g.width, g.height
+}
+
+// SetRule changes the rule used for the following generations
+func (g *Grid) SetRule(r Rule) {
+	g.rule = r
+}
+
+// Rule returns the rule the grid runs under
+func (g *Grid) Rule() Rule {
+	return g.rule
+}
+
+// SetWrap chooses whether the edges wrap around (the default) or are a
+// dead border
+func (g *Grid) SetWrap(wrap bool) {
+	g.bounded = !wrap
+}
+
+// NewGridWithPattern creates a grid with a specific pattern
+func NewGridWithPattern(pattern string) *Grid {
+	g := NewSizedGrid(Width, Height)
+	g.Place(pattern)
+	return g
+}
+
+// set brings the cell at (x, y) to life. Coordinates past the edges wrap,
+// so the patterns fit on grids smaller than a panel too.
+func (g *Grid) set(x, y int) {
+	x = (x%g.width + g.width) % g.width
+	y = (y%g.height + g.height) % g.height
+	g.cells[y][x] = true
+}
+
+// Place puts a pattern, centred, on an empty grid. Patterns it doesn't
+// know (and "random") fill it at random instead.
+func (g *Grid) Place(pattern string) {
+	switch pattern {
+	case "glider":
+		// Place a glider in the center
+		cx, cy := g.width/2, g.height/2
+		g.set(cx+1, cy)
+		g.set(cx+2, cy+1)
+		g.set(cx, cy+2)
+		g.set(cx+1, cy+2)
+		g.set(cx+2, cy+2)
+
+	case "blinker":
+		// Place a blinker in the center
+		cx, cy := g.width/2, g.height/2
+		g.set(cx-1, cy)
+		g.set(cx, cy)
+		g.set(cx+1, cy)
+
+	case "toad":
+		// Place a toad oscillator
+		cx, cy := g.width/2, g.height/2
+		g.set(cx, cy)
+		g.set(cx+1, cy)
+		g.set(cx+2, cy)
+		g.set(cx-1, cy+1)
+		g.set(cx, cy+1)
+		g.set(cx+1, cy+1)
+
+	case "pulsar":
+		// Place a pulsar pattern
+		cx, cy := g.width/2, g.height/2
+		// Top half
+		for i := 0; i < 3; i++ {
+			g.set(cx-4+i, cy-6)
+			g.set(cx+2+i, cy-6)
+			g.set(cx-4+i, cy-1)
+			g.set(cx+2+i, cy-1)
+		}
+		// Bottom half (mirror)
+		for i := 0; i < 3; i++ {
+			g.set(cx-4+i, cy+1)
+			g.set(cx+2+i, cy+1)
+			g.set(cx-4+i, cy+6)
+			g.set(cx+2+i, cy+6)
+		}
+		// Left side
+		for i := 0; i < 3; i++ {
+			g.set(cx-6, cy-4+i)
+			g.set(cx-6, cy+2+i)
+			g.set(cx-1, cy-4+i)
+			g.set(cx-1, cy+2+i)
+		}
+		// Right side
+		for i := 0; i < 3; i++ {
+			g.set(cx+1, cy-4+i)
+			g.set(cx+1, cy+2+i)
+			g.set(cx+6, cy-4+i)
+			g.set(cx+6, cy+2+i)
+		}
+
+	case "lightweight_spaceship":
+		// LWSS - moves horizontally
+		cx, cy := g.width/2, g.height/2
+		g.set(cx+1, cy)
+		g.set(cx+4, cy)
+		g.set(cx, cy+1)
+		g.set(cx, cy+2)
+		g.set(cx+4, cy+2)
+		g.set(cx, cy+3)
+		g.set(cx+1, cy+3)
+		g.set(cx+2, cy+3)
+		g.set(cx+3, cy+3)
+
+	case "gosper_glider_gun":
+		// Famous pattern that continuously produces gliders
+		// Left square
+		g.set(24, 20)
+		g.set(25, 20)
+		g.set(24, 21)
+		g.set(25, 21)
+
+		// Left part
+		g.set(34, 20)
+		g.set(34, 21)
+		g.set(34, 22)
+		g.set(35, 19)
+		g.set(35, 23)
+		g.set(36, 18)
+		g.set(36, 24)
+		g.set(37, 18)
+		g.set(37, 24)
+		g.set(38, 21)
+		g.set(39, 19)
+		g.set(39, 23)
+		g.set(40, 20)
+		g.set(40, 21)
+		g.set(40, 22)
+		g.set(41, 21)
+
+		// Right part
+		g.set(44, 18)
+		g.set(44, 19)
+		g.set(44, 20)
+		g.set(45, 18)
+		g.set(45, 19)
+		g.set(45, 20)
+		g.set(46, 17)
+		g.set(46, 21)
+		g.set(48, 16)
+		g.set(48, 17)
+		g.set(48, 21)
+		g.set(48, 22)
+
+		// Right square
+		g.set(58, 18)
+		g.set(58, 19)
+		g.set(59, 18)
+		g.set(59, 19)
+
+	case "explosion":
+		// Creates chaotic explosions across the screen
+		cx, cy := g.width/2, g.height/2
+		// Multiple R-pentominos (famous for chaotic behavior)
+		for i := 0; i < 3; i++ {
+			ox, oy := cx-40+i*40, cy-10+i*10
+			g.set(ox+1, oy)
+			g.set(ox+2, oy)
+			g.set(ox, oy+1)
+			g.set(ox+1, oy+1)
+			g.set(ox+1, oy+2)
+		}
+
+	case "traffic_lights":
+		// Multiple oscillators creating a light show
+		for y := 10; y < g.height-10; y += 15 {
+			for x := 10; x < g.width-10; x += 20 {
+				// Blinker
+				g.set(x, y)
+				g.set(x+1, y)
+				g.set(x+2, y)
+			}
+		}
+		for y := 18; y < g.height-10; y += 15 {
+			for x := 15; x < g.width-10; x += 20 {
+				// Toad
+				g.set(x, y)
+				g.set(x+1, y)
+				g.set(x+2, y)
+				g.set(x-1, y+1)
+				g.set(x, y+1)
+				g.set(x+1, y+1)
+			}
+		}
+
+	case "acorn":
+		// Small pattern that evolves for 5000+ generations
+		cx, cy := g.width/2, g.height/2
+		g.set(cx+1, cy)
+		g.set(cx+3, cy+1)
+		g.set(cx, cy+2)
+		g.set(cx+1, cy+2)
+		g.set(cx+4, cy+2)
+		g.set(cx+5, cy+2)
+		g.set(cx+6, cy+2)
+
+	case "fireworks":
+		// Multiple gliders shooting in all directions
+		cx, cy := g.width/2, g.height/2
+		// Center explosion
+		for i := 0; i < 8; i++ {
+			angle := i * 45
+			offsetX, offsetY := 0, 0
+			switch angle {
+			case 0:
+				offsetX, offsetY = 15, 0
+			case 45:
+				offsetX, offsetY = 10, -10
+			case 90:
+				offsetX, offsetY = 0, -15
+			case 135:
+				offsetX, offsetY = -10, -10
+			case 180:
+				offsetX, offsetY = -15, 0
+			case 225:
+				offsetX, offsetY = -10, 10
+			case 270:
+				offsetX, offsetY = 0, 15
+			case 315:
+				offsetX, offsetY = 10, 10
+			}
+			x, y := cx+offsetX, cy+offsetY
+			g.set(x+1, y)
+			g.set(x+2, y+1)
+			g.set(x, y+2)
+			g.set(x+1, y+2)
+			g.set(x+2, y+2)
+		}
+
+	case "spaceship_fleet":
+		// Multiple spaceships moving together
+		for i := 0; i < 4; i++ {
+			cx, cy := 20+i*25, 10+i*10
+			// LWSS
+			g.set(cx+1, cy)
+			g.set(cx+4, cy)
+			g.set(cx, cy+1)
+			g.set(cx, cy+2)
+			g.set(cx+4, cy+2)
+			g.set(cx, cy+3)
+			g.set(cx+1, cy+3)
+			g.set(cx+2, cy+3)
+			g.set(cx+3, cy+3)
+		}
+
+	case "dense_chaos":
+		// 50% density random - maximum chaos!
+		g.Randomize(50)
+
+	default:
+		// Random initialization
+		g.Randomize(30)
+	}
+}
+
+// PlaceCells puts a pattern read from a file (an rle.Pattern), centred,
+// on an empty grid. Cells past the edges wrap.
+func (g *Grid) PlaceCells(p rle.Board) {
+	w, h := p.Size()
+	left, top := (g.width-w)/2, (g.height-h)/2
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if p.Alive(x, y) {
+				g.set(left+x, top+y)
+			}
+		}
+	}
+}
+
+// Alive reports whether the cell at (x, y) is alive, so a grid can be
+// saved with rle.Encode
+func (g *Grid) Alive(x, y int) bool {
+	if x < 0 || x >= g.width || y < 0 || y >= g.height {
+		return false
+	}
+	return g.cells[y][x]
+}
+
+// CountNeighbors counts the live neighbors of a cell at (x, y)
+func (g *Grid) CountNeighbors(x, y int) int {
+	count := 0
+
+	// Check all 8 neighbors
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			if dx == 0 && dy == 0 {
+				continue // Skip the cell itself
+			}
+
+			nx, ny := x+dx, y+dy
+			if g.bounded {
+				// Past the edge is dead
+				if nx < 0 || nx >= g.width || ny < 0 || ny >= g.height {
+					continue
+				}
+			} else {
+				// Wrap around the edges
+				nx = (nx + g.width) % g.width
+				ny = (ny + g.height) % g.height
+			}
+
+			if g.cells[ny][nx] {
+				count++
+			}
+		}
+	}
+
+	return count
+}
+
+// Next computes the next generation of the grid
+func (g *Grid) Next() *Grid {
+	next := NewSizedGrid(g.width, g.height)
+	next.rule, next.bounded = g.rule, g.bounded
+
+	for y := 0; y < g.height; y++ {
+		for x := 0; x < g.width; x++ {
+			neighbors := g.CountNeighbors(x, y)
+			alive := g.cells[y][x]
+
+			// Apply the rule (Conway's unless changed)
+			next.cells[y][x] = g.rule.next(alive, neighbors)
+		}
+	}
+
+	return next
+}
+
+// DrawToOLED renders the grid directly to the SSD1306 OLED display
+func (g *Grid) DrawToOLED(display display.Display) {
+	g.Draw(display)
+
+	// Send buffer to display
+	display.Display()
+}
+
+// Draw renders the grid into the display buffer without sending it, so
+// something can be drawn over it first
+func (g *Grid) Draw(display display.Display) {
+	// Clear the display buffer
+	display.ClearBuffer()
+
+	// Set each pixel based on cell state
+	for y := 0; y < g.height; y++ {
+		for x := 0; x < g.width; x++ {
+			if g.cells[y][x] {
+				display.SetPixel(int16(x), int16(y), color.RGBA{255, 255, 255, 255}) // White pixel
+			}
+		}
+	}
+}
+
+// CountLiveCells returns the number of live cells
+func (g *Grid) CountLiveCells() int {
+	count := 0
+	for y := 0; y < g.height; y++ {
+		for x := 0; x < g.width; x++ {
+			if g.cells[y][x] {
+				count++
+			}
+		}
+	}
+	return count
+}
@@ -0,0 +1,88 @@
+package life
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"tinygo.org/x/drivers/ssd1306"
+
+	"gameoflife/display"
+	"gameoflife/ssd1306emu"
+)
+
+var pngDir = flag.String("png", "", "save the panels the tests draw as PNGs in this directory")
+
+// emulatedOLED is the real SSD1306 driver on an emulated 128x64 panel
+func emulatedOLED(t *testing.T) (*ssd1306.Device, *ssd1306emu.Device) {
+	t.Helper()
+	bus := ssd1306emu.NewBus()
+	oled := bus.Attach(0x3C, 128, 64)
+	dev := ssd1306.NewI2C(bus)
+	dev.Configure(ssd1306.Config{Address: 0x3C, Width: 128, Height: 64})
+	return dev, oled
+}
+
+// checkPNG saves what the panel shows as a PNG, reads it back and checks
+// it lights the cells that are alive
+func checkPNG(t *testing.T, oled *ssd1306emu.Device, g *Grid, name string) {
+	t.Helper()
+	var b bytes.Buffer
+	if err := oled.WritePNG(&b, 1); err != nil {
+		t.Fatal(err)
+	}
+	if *pngDir != "" {
+		if err := os.MkdirAll(*pngDir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(*pngDir, name+".png"), b.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	img, err := png.Decode(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for y := 0; y < g.height; y++ {
+		for x := 0; x < g.width; x++ {
+			r, _, _, _ := img.At(x, y).RGBA()
+			if lit := r != 0; lit != g.Alive(x, y) {
+				t.Fatalf("%s: PNG pixel (%d, %d) lit %v, cell alive %v", name, x, y, lit, g.Alive(x, y))
+			}
+		}
+	}
+}
+
+func TestDrawToOLED(t *testing.T) {
+	dev, oled := emulatedOLED(t)
+	g := NewSizedGrid(128, 64)
+	g.Place("glider")
+	for gen := 0; gen < 8; gen++ {
+		g.DrawToOLED(dev)
+
+		// The panel's RAM holds what a framebuffer does, byte for byte
+		fb := display.NewFramebuffer(128, 64)
+		g.Draw(fb)
+		if !bytes.Equal(oled.RAM(), fb.Buffer()) {
+			t.Fatalf("generation %d: panel RAM differs from the framebuffer", gen)
+		}
+		for y := 0; y < 64; y++ {
+			for x := 0; x < 128; x++ {
+				if oled.Pixel(x, y) != g.Alive(x, y) {
+					t.Fatalf("generation %d: pixel (%d, %d) shows %v", gen, x, y, oled.Pixel(x, y))
+				}
+			}
+		}
+		if gen == 0 || gen == 7 {
+			checkPNG(t, oled, g, fmt.Sprintf("glider-%d", gen))
+		}
+		g = g.Next()
+	}
+	if g.CountLiveCells() != 5 {
+		t.Errorf("%d cells alive after 8 generations, want the glider's 5", g.CountLiveCells())
+	}
+}
@@ -0,0 +1,102 @@
+package life
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Rule says which neighbour counts bring a dead cell to life (birth) and
+// which keep a live cell alive (survival), written as "B3/S23"
+type Rule struct {
+	Name    string
+	birth   [9]bool
+	survive [9]bool
+}
+
+// Built-in rules
+var (
+	// Conway is the classic Game of Life: a dead cell with exactly 3
+	// neighbours is born, a live cell with 2 or 3 survives, anything else
+	// dies of underpopulation or overpopulation
+	Conway = MustParseRule("Conway", "B3/S23")
+
+	// HighLife also gives birth on 6 neighbours, which lets a small
+	// replicator copy itself across the board
+	HighLife = MustParseRule("HighLife", "B36/S23")
+
+	// DayNight is symmetric: swapping live and dead cells gives the same
+	// behaviour
+	DayNight = MustParseRule("Day & Night", "B3678/S34678")
+
+	// Seeds has no survival at all, so everything explodes
+	Seeds = MustParseRule("Seeds", "B2/S")
+)
+
+// Rules are the built-in rules, Conway first
+var Rules = []Rule{Conway, HighLife, DayNight, Seeds}
+
+// ParseRule reads a rule in B/S notation such as "B36/S23" (the order of
+// the two halves and the case of B and S don't matter)
+func ParseRule(name, s string) (Rule, error) {
+	r := Rule{Name: name}
+	parts := strings.Split(strings.ToUpper(strings.TrimSpace(s)), "/")
+	if len(parts) != 2 {
+		return Rule{}, fmt.Errorf("life: rule %q is not in B/S form", s)
+	}
+	seen := map[byte]bool{}
+	for _, part := range parts {
+		if part == "" || (part[0] != 'B' && part[0] != 'S') || seen[part[0]] {
+			return Rule{}, fmt.Errorf("life: rule %q is not in B/S form", s)
+		}
+		seen[part[0]] = true
+		counts := &r.birth
+		if part[0] == 'S' {
+			counts = &r.survive
+		}
+		for _, c := range part[1:] {
+			if c < '0' || c > '8' {
+				return Rule{}, fmt.Errorf("life: bad neighbour count %q in rule %q", c, s)
+			}
+			counts[c-'0'] = true
+		}
+	}
+	if r.Name == "" {
+		r.Name = r.String()
+	}
+	return r, nil
+}
+
+// MustParseRule is ParseRule for rules known to be valid
+func MustParseRule(name, s string) Rule {
+	r, err := ParseRule(name, s)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// String returns the rule in B/S notation
+func (r Rule) String() string {
+	var b strings.Builder
+	b.WriteByte('B')
+	for n, ok := range r.birth {
+		if ok {
+			b.WriteByte(byte('0' + n))
+		}
+	}
+	b.WriteString("/S")
+	for n, ok := range r.survive {
+		if ok {
+			b.WriteByte(byte('0' + n))
+		}
+	}
+	return b.String()
+}
+
+// next returns whether a cell is alive in the next generation
+func (r *Rule) next(alive bool, neighbors int) bool {
+	if alive {
+		return r.survive[neighbors]
+	}
+	return r.birth[neighbors]
+}
@@ -0,0 +1,39 @@
+package life
+
+import (
+	"fmt"
+
+	"gameoflife/settings"
+	"gameoflife/ui"
+)
+
+// NewGame creates a width x height grid for a pattern with the game
+// settings (density, wrap and rule) applied
+func NewGame(s settings.Settings, pattern string, width, height int) *Grid {
+	g := NewSizedGrid(width, height)
+	if pattern == "random" {
+		g.Randomize(s.Density)
+	} else {
+		g.Place(pattern)
+	}
+	g.SetWrap(s.Wrap)
+	if s.Rule >= 0 && s.Rule < len(Rules) {
+		g.SetRule(Rules[s.Rule])
+	}
+	return g
+}
+
+// CheckSettings checks the settings that index the game's tables (the
+// pattern, rule and HUD mode), which settings.Validate can't know the
+// sizes of. Both firmwares check a "set" with it, as they share settings.
+func CheckSettings(s settings.Settings) error {
+	switch {
+	case s.Pattern >= len(patterns):
+		return fmt.Errorf("life: pattern %d out of range", s.Pattern)
+	case s.Rule >= len(Rules):
+		return fmt.Errorf("life: rule %d out of range", s.Rule)
+	case s.HUD >= len(ui.HUDModes):
+		return fmt.Errorf("life: HUD mode %d out of range", s.HUD)
+	}
+	return nil
+}
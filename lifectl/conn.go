@@ -0,0 +1,148 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"gameoflife/telemetry"
+)
+
+// quiet is how long the port has to be silent for the output already
+// waiting (the log since boot, say) to count as read
+const quiet = 200 * time.Millisecond
+
+// logLine matches the firmware's log lines, "12.345 INFO  [GAME] ..."
+var logLine = regexp.MustCompile(`^\d+\.\d{3} (DEBUG|INFO|WARN|ERROR) `)
+
+// item is one thing read from the port: a telemetry record, a line of
+// other output, or the error that ended reading
+type item struct {
+	rec  *telemetry.Record
+	line string
+	err  error
+}
+
+// conn is a connection to the firmware's console
+type conn struct {
+	port    io.ReadWriteCloser
+	items   chan item
+	log     io.Writer     // where the firmware's log lines go
+	timeout time.Duration // for an answer to a command
+}
+
+// dial opens the serial port at path and waits for it to go quiet, so
+// answers aren't mixed up with what was said before
+func dial(path string, timeout time.Duration) (*conn, error) {
+	port, err := openPort(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &conn{port: port, items: make(chan item, 64), log: io.Discard, timeout: timeout}
+	go c.read()
+
+	// An empty line ends anything typed before without being a command
+	if _, err := io.WriteString(port, "\n"); err != nil {
+		port.Close()
+		return nil, err
+	}
+	for {
+		select {
+		case it := <-c.items:
+			if it.err != nil {
+				port.Close()
+				return nil, it.err
+			}
+			c.show(it)
+			continue
+		case <-time.After(quiet):
+		}
+		return c, nil
+	}
+}
+
+// read passes everything that arrives to items until the port closes
+func (c *conn) read() {
+	r := telemetry.NewReader(c.port)
+	for {
+		rec, line, err := r.Next()
+		if err != nil && !errors.Is(err, telemetry.ErrMalformed) {
+			c.items <- item{err: err}
+			return
+		}
+		if err == nil {
+			c.items <- item{rec: rec, line: line}
+		}
+	}
+}
+
+// show passes a log line on to c.log; anything else read outside a
+// command is dropped
+func (c *conn) show(it item) {
+	if it.rec == nil && logLine.MatchString(it.line) {
+		fmt.Fprintln(c.log, it.line)
+	}
+}
+
+// command sends a console command, with lines of body after it, and
+// returns its output. The firmware's "error: ..." answers are errors.
+func (c *conn) command(cmd string, body ...string) ([]string, error) {
+	msg := cmd + "\n"
+	for _, line := range body {
+		msg += line + "\n"
+	}
+	if _, err := io.WriteString(c.port, msg); err != nil {
+		return nil, err
+	}
+
+	var out []string
+	deadline := time.After(c.timeout)
+	for {
+		select {
+		case it := <-c.items:
+			switch {
+			case it.err != nil:
+				return out, it.err
+			case it.rec != nil, logLine.MatchString(it.line):
+				c.show(it)
+			case it.line == "ok":
+				return out, nil
+			case strings.HasPrefix(it.line, "error: "):
+				return out, errors.New(strings.TrimPrefix(it.line, "error: "))
+			case it.line != "":
+				out = append(out, it.line)
+			}
+		case <-deadline:
+			return out, fmt.Errorf("no answer to %q in %v (is the firmware running?)", cmd, c.timeout)
+		}
+	}
+}
+
+// records passes the telemetry records that arrive to f until stop is
+// closed, and log lines to c.log
+func (c *conn) records(stop <-chan os.Signal, f func(*telemetry.Record)) error {
+	for {
+		select {
+		case it := <-c.items:
+			if it.err != nil {
+				return it.err
+			}
+			if it.rec != nil {
+				f(it.rec)
+			} else {
+				c.show(it)
+			}
+		case <-stop:
+			return nil
+		}
+	}
+}
+
+// Close closes the port
+func (c *conn) Close() error {
+	return c.port.Close()
+}
@@ -0,0 +1,202 @@
+// lifectl talks to the Game of Life and Pong firmwares over the board's
+// USB serial port, through their console:
+//
+//	lifectl upload glider.rle          # play an RLE pattern
+//	lifectl fetch -o board.rle         # save the board as RLE
+//	lifectl telemetry -csv > run.csv   # record the numbers until Ctrl+C
+//	lifectl set speed=50 wrap=off      # change and save settings
+//	lifectl settings                   # list them
+//	lifectl send pattern glider        # any console command
+//
+// The port is -port, or $LIFECTL_PORT, or /dev/ttyUSB0. Without the board
+// attached, run the simulator with a stand-in port and point lifectl at
+// it:
+//
+//	go run ./simulator -serial /tmp/gol-serial 2>sim.log
+//	LIFECTL_PORT=/tmp/gol-serial go run ./lifectl fetch
+//
+// -v shows the firmware's log lines on stderr as they arrive.
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"gameoflife/rle"
+	"gameoflife/telemetry"
+)
+
+const usage = `usage: lifectl [-port path] [-v] <command> [arguments]
+
+commands:
+  upload <file.rle|->          play an RLE pattern
+  fetch [-o file.rle]          print or save the board as RLE
+  telemetry [-cbor] [-csv]     print telemetry until Ctrl+C
+  set <name>=<value>...        change and save settings
+  settings                     list the settings
+  send <command>...            send a console command, print its output
+`
+
+func main() {
+	port := flag.String("port", defaultPort(), "serial port of the board, or the simulator's -serial path")
+	verbose := flag.Bool("v", false, "show the firmware's log on stderr")
+	timeout := flag.Duration("timeout", 5*time.Second, "how long to wait for an answer")
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage, "\nflags:\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	c, err := dial(*port, *timeout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "lifectl:", err)
+		os.Exit(1)
+	}
+	defer c.Close()
+	if *verbose {
+		c.log = os.Stderr
+	}
+
+	args := flag.Args()[1:]
+	switch flag.Arg(0) {
+	case "upload":
+		err = upload(c, args)
+	case "fetch":
+		err = fetch(c, args)
+	case "telemetry":
+		err = stream(c, args)
+	case "set":
+		err = printOutput(c.command("set " + strings.Join(args, " ")))
+	case "settings":
+		err = printOutput(c.command("settings"))
+	case "send":
+		err = printOutput(c.command(strings.Join(args, " ")))
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "lifectl:", err)
+		os.Exit(1)
+	}
+}
+
+// defaultPort is $LIFECTL_PORT, or the ESP32's usual port on Linux
+func defaultPort() string {
+	if p := os.Getenv("LIFECTL_PORT"); p != "" {
+		return p
+	}
+	return "/dev/ttyUSB0"
+}
+
+// printOutput prints a command's output
+func printOutput(out []string, err error) error {
+	for _, line := range out {
+		fmt.Println(line)
+	}
+	return err
+}
+
+// upload sends an RLE pattern with "load". It is read here first, so a
+// bad file is reported without a round trip, and sent re-encoded, in the
+// short lines the console takes.
+func upload(c *conn, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: lifectl upload <file.rle|->")
+	}
+	var r io.Reader = os.Stdin
+	if args[0] != "-" {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	p, err := rle.Decode(r)
+	if err != nil {
+		return err
+	}
+	var b strings.Builder
+	if err := rle.EncodeRule(&b, p, p.Name, p.Rule); err != nil {
+		return err
+	}
+	return printOutput(c.command("load", strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")...))
+}
+
+// fetch saves the board, as "grid" prints it
+func fetch(c *conn, args []string) error {
+	fs := flag.NewFlagSet("fetch", flag.ExitOnError)
+	out := fs.String("o", "", "file to save the pattern in (default: print it)")
+	fs.Parse(args)
+
+	lines, err := c.command("grid")
+	if err != nil {
+		return err
+	}
+	text := strings.Join(lines, "\n") + "\n"
+	if *out == "" {
+		_, err = os.Stdout.WriteString(text)
+		return err
+	}
+	return os.WriteFile(*out, []byte(text), 0o644)
+}
+
+// stream turns telemetry on, prints the records as JSON lines or CSV
+// until Ctrl+C, and turns it off again
+func stream(c *conn, args []string) error {
+	fs := flag.NewFlagSet("telemetry", flag.ExitOnError)
+	useCBOR := fs.Bool("cbor", false, "have the firmware send CBOR (printed as JSON all the same)")
+	useCSV := fs.Bool("csv", false, "print CSV, with a header whenever the columns change")
+	fs.Parse(args)
+
+	format := "json"
+	if *useCBOR {
+		format = "cbor"
+	}
+	if _, err := c.command("telemetry " + format); err != nil {
+		return err
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt)
+	w := csv.NewWriter(os.Stdout)
+	var header []string
+	err := c.records(stop, func(rec *telemetry.Record) {
+		if !*useCSV {
+			b, _ := json.Marshal(rec)
+			fmt.Printf("%s\n", b)
+			return
+		}
+		columns := []string{"t", "k"}
+		row := []string{strconv.FormatInt(rec.Time, 10), rec.Kind}
+		for _, f := range rec.Fields {
+			columns = append(columns, f.Name)
+			row = append(row, f.Value())
+		}
+		if strings.Join(columns, ",") != strings.Join(header, ",") {
+			header = columns
+			w.Write(header)
+		}
+		w.Write(row)
+		w.Flush()
+	})
+	signal.Stop(stop)
+
+	if _, offErr := c.command("telemetry off"); err == nil {
+		err = offErr
+	}
+	return err
+}
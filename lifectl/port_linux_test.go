@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"gameoflife/console"
+	"gameoflife/telemetry"
+	"gameoflife/terminal"
+)
+
+// firmware stands in for the board on the other end of a pseudo-terminal,
+// answering through a console as the games do
+type firmware struct {
+	pty    *terminal.PTY
+	loaded chan string // the patterns loaded
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+func startFirmware(t *testing.T) *firmware {
+	t.Helper()
+	pty, err := terminal.OpenPTY()
+	if err != nil {
+		t.Skip("no pseudo-terminal:", err)
+	}
+	f := &firmware{pty: pty, loaded: make(chan string, 1), stop: make(chan struct{}), done: make(chan struct{})}
+	pty.Write([]byte("0.001 INFO  [BOOT] Started\n"))
+	go f.run()
+	t.Cleanup(func() {
+		close(f.stop)
+		<-f.done
+		pty.Close()
+	})
+	return f
+}
+
+func (f *firmware) run() {
+	defer close(f.done)
+	con := console.New([]console.Command{
+		{Name: "grid"},
+		{Name: "load", Body: true},
+		{Name: "hang"},
+		{Name: "records"},
+	}, f.pty)
+	for {
+		select {
+		case <-f.stop:
+			return
+		case <-time.After(time.Millisecond):
+		}
+		for req, ok := con.Next(); ok; req, ok = con.Next() {
+			switch req.Name {
+			case "grid":
+				// The log and telemetry go on while a command is answered
+				con.Print("x = 3, y = 1, rule = B3/S23")
+				f.pty.Write([]byte("1.250 DEBUG [LIFE] Grid sent\n"))
+				f.pty.Write([]byte(`{"t":1250,"k":"life","gen":7}` + "\n"))
+				con.Print("3o!")
+				con.Done(nil)
+			case "load":
+				f.loaded <- req.Body
+				con.Done(nil)
+			case "hang":
+				// never answered
+			case "records":
+				con.Done(nil)
+				for gen := 1; gen <= 3; gen++ {
+					f.pty.Write([]byte(`{"t":100,"k":"life","gen":` + strconv.Itoa(gen) + "}\n"))
+				}
+			}
+		}
+	}
+}
+
+func dialFirmware(t *testing.T, f *firmware) (*conn, *bytes.Buffer) {
+	t.Helper()
+	c, err := dial(f.pty.Name, 2*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	var log bytes.Buffer
+	c.log = &log
+	return c, &log
+}
+
+func TestCommandOverPTY(t *testing.T) {
+	f := startFirmware(t)
+	c, log := dialFirmware(t, f)
+
+	out, err := c.command("grid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "x = 3, y = 1, rule = B3/S23\n3o!"; strings.Join(out, "\n") != want {
+		t.Errorf("output %q, want %q", out, want)
+	}
+	if want := "1.250 DEBUG [LIFE] Grid sent\n"; log.String() != want {
+		t.Errorf("log %q, want %q", log.String(), want)
+	}
+
+	_, err = c.command("fly")
+	if want := `console: unknown command "fly" (try help)`; err == nil || err.Error() != want {
+		t.Errorf("error %v, want %s", err, want)
+	}
+
+	c.timeout = 300 * time.Millisecond
+	if _, err := c.command("hang"); err == nil || !strings.Contains(err.Error(), "no answer") {
+		t.Errorf("error %v, want no answer", err)
+	}
+}
+
+func TestUploadOverPTY(t *testing.T) {
+	f := startFirmware(t)
+	c, _ := dialFirmware(t, f)
+
+	path := filepath.Join(t.TempDir(), "glider.rle")
+	glider := "#N Glider\n#C long comments aren't sent\nx = 3, y = 3\nbob$2bo$3o!\n"
+	if err := os.WriteFile(path, []byte(glider), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := upload(c, []string{path}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case body := <-f.loaded:
+		if want := "#N Glider\nx = 3, y = 3, rule = B3/S23\nbo$2bo$3o!\n"; body != want {
+			t.Errorf("loaded %q, want %q", body, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("nothing loaded")
+	}
+}
+
+func TestFetchOverPTY(t *testing.T) {
+	f := startFirmware(t)
+	c, _ := dialFirmware(t, f)
+
+	path := filepath.Join(t.TempDir(), "board.rle")
+	if err := fetch(c, []string{"-o", path}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "x = 3, y = 1, rule = B3/S23\n3o!\n"; string(got) != want {
+		t.Errorf("saved %q, want %q", got, want)
+	}
+}
+
+func TestRecordsOverPTY(t *testing.T) {
+	f := startFirmware(t)
+	c, _ := dialFirmware(t, f)
+
+	if _, err := c.command("records"); err != nil {
+		t.Fatal(err)
+	}
+	stop := make(chan os.Signal)
+	var gens []string
+	err := c.records(stop, func(rec *telemetry.Record) {
+		gen, _ := rec.Get("gen")
+		gens = append(gens, gen.Value())
+		if len(gens) == 3 {
+			close(stop)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(gens, ",") != "1,2,3" {
+		t.Errorf("generations %q", gens)
+	}
+}
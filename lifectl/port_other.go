@@ -0,0 +1,14 @@
+//go:build !linux
+
+package main
+
+import (
+	"io"
+	"os"
+)
+
+// openPort opens a serial port as it is. Set it to 115200 baud, 8N1, raw
+// first (stty -f /dev/cu.usbserial-0001 115200 raw on macOS).
+func openPort(path string) (io.ReadWriteCloser, error) {
+	return os.OpenFile(path, os.O_RDWR, 0)
+}
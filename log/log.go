@@ -0,0 +1,177 @@
+// Package log is the firmware's serial log: lines with a time, a level
+// and a tag, small enough for TinyGo.
+//
+//	var gameLog = log.New("GAME")
+//	gameLog.Info("Switched to:", name)
+//
+// prints
+//
+//	12.345 INFO  [GAME] Switched to: GLIDER
+//
+// The time is seconds since boot. Lines below the level set for their tag
+// (SetTagLevel) or for everything (SetLevel) aren't printed. Arguments are
+// joined with spaces, as println does.
+package log
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level is how much a line matters
+type Level int8
+
+const (
+	Debug Level = iota
+	Info
+	Warn
+	Error
+	Off // for SetLevel: print nothing
+)
+
+var levelNames = []string{"DEBUG", "INFO", "WARN", "ERROR", "OFF"}
+
+// String names the level, as it is printed
+func (l Level) String() string {
+	if l < 0 || int(l) >= len(levelNames) {
+		return "?"
+	}
+	return levelNames[l]
+}
+
+// ParseLevel finds a level by name, in any case
+func ParseLevel(name string) (Level, error) {
+	for i, n := range levelNames {
+		if strings.EqualFold(n, name) {
+			return Level(i), nil
+		}
+	}
+	return Info, errors.New("log: unknown level " + strconv.Quote(name))
+}
+
+var (
+	mu        sync.Mutex
+	output    io.Writer = os.Stderr // the serial port on the board, as println
+	level               = Info
+	tagLevels           = map[string]Level{}
+	start               = time.Now()
+	buf       []byte
+)
+
+// SetOutput sends the log to w
+func SetOutput(w io.Writer) {
+	mu.Lock()
+	output = w
+	mu.Unlock()
+}
+
+// SetLevel sets the lowest level printed, for tags without their own
+func SetLevel(l Level) {
+	mu.Lock()
+	level = l
+	mu.Unlock()
+}
+
+// SetTagLevel sets the lowest level printed for one tag
+func SetTagLevel(tag string, l Level) {
+	mu.Lock()
+	tagLevels[tag] = l
+	mu.Unlock()
+}
+
+// Logger prints lines with a tag
+type Logger struct {
+	tag string
+}
+
+// New creates a logger for tag, such as "GAME"
+func New(tag string) *Logger {
+	return &Logger{tag: tag}
+}
+
+// Debug prints a line for following what the code does in detail
+func (l *Logger) Debug(args ...any) { l.print(Debug, args) }
+
+// Info prints a line about something that happened
+func (l *Logger) Info(args ...any) { l.print(Info, args) }
+
+// Warn prints a line about something that went wrong but was handled
+func (l *Logger) Warn(args ...any) { l.print(Warn, args) }
+
+// Error prints a line about something that stopped working
+func (l *Logger) Error(args ...any) { l.print(Error, args) }
+
+// Enabled reports whether lines at lvl are printed, to skip work done
+// only for the log
+func (l *Logger) Enabled(lvl Level) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return l.enabled(lvl)
+}
+
+func (l *Logger) enabled(lvl Level) bool {
+	floor, ok := tagLevels[l.tag]
+	if !ok {
+		floor = level
+	}
+	return lvl >= floor && lvl < Off
+}
+
+func (l *Logger) print(lvl Level, args []any) {
+	mu.Lock()
+	defer mu.Unlock()
+	if !l.enabled(lvl) {
+		return
+	}
+
+	ms := time.Since(start).Milliseconds()
+	buf = strconv.AppendInt(buf[:0], ms/1000, 10)
+	buf = append(buf, '.')
+	buf = append(buf, byte('0'+ms/100%10), byte('0'+ms/10%10), byte('0'+ms%10), ' ')
+	name := lvl.String()
+	buf = append(buf, name...)
+	for i := len(name); i < 6; i++ {
+		buf = append(buf, ' ')
+	}
+	buf = append(buf, '[')
+	buf = append(buf, l.tag...)
+	buf = append(buf, ']')
+	for _, a := range args {
+		buf = append(buf, ' ')
+		buf = appendArg(buf, a)
+	}
+	buf = append(buf, '\n')
+	output.Write(buf)
+}
+
+// appendArg formats one argument the way println would, avoiding fmt for
+// the common types
+func appendArg(b []byte, a any) []byte {
+	switch v := a.(type) {
+	case string:
+		return append(b, v...)
+	case int:
+		return strconv.AppendInt(b, int64(v), 10)
+	case int16:
+		return strconv.AppendInt(b, int64(v), 10)
+	case int64:
+		return strconv.AppendInt(b, v, 10)
+	case uint8:
+		return strconv.AppendUint(b, uint64(v), 10)
+	case bool:
+		return strconv.AppendBool(b, v)
+	case float32:
+		return strconv.AppendFloat(b, float64(v), 'g', 4, 32)
+	case error:
+		return append(b, v.Error()...)
+	case fmt.Stringer:
+		return append(b, v.String()...)
+	}
+	return fmt.Append(b, a)
+}
@@ -0,0 +1,179 @@
+// Package pacing runs the game loops at a steady rate.
+//
+// A Loop separates the simulation from the drawing: the game advances in
+// fixed steps of simulated time (a Life generation, a Pong physics tick)
+// and draws frames at a target rate. However long a frame takes to
+// compute and send over I2C, the simulation keeps to real time. When
+// frames fall behind, the late ones are skipped and the next frame runs
+// the steps they would have.
+//
+//	loop := pacing.NewLoop(50*time.Millisecond, 20*time.Millisecond)
+//	for {
+//		for n := loop.Begin(); n > 0; n-- {
+//			update() // one 20ms step
+//		}
+//		draw()
+//		loop.End() // sleeps until the next frame is due
+//	}
+package pacing
+
+import (
+	"strconv"
+	"time"
+
+	"gameoflife/input"
+)
+
+// Clock tells the time and sleeps. An input.Script is one, so a Loop can
+// run on simulated time.
+type Clock interface {
+	input.Clock
+	Sleep(d time.Duration)
+}
+
+// SystemClock is the real clock
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+// DefaultMaxSteps is how many steps a frame runs at most before the
+// simulation gives up on catching up
+const DefaultMaxSteps = 4
+
+// Loop paces a game loop: Begin at the start of each frame says how many
+// simulation steps to run, and End at the end of it waits for the next
+// frame
+type Loop struct {
+	Frame    time.Duration // target time from one frame to the next
+	Step     time.Duration // simulated time per step
+	MaxSteps int           // steps per frame at most; time past them is dropped
+
+	clock       Clock
+	begun       time.Time     // when the current frame began
+	next        time.Time     // when the next frame is due
+	accumulated time.Duration // real time not yet simulated
+	skipped     int
+	dropped     int
+
+	window      time.Time // start of the current stats window
+	frames      int
+	steps       int
+	work, worst time.Duration
+	stats       Stats
+}
+
+// Stats measures a Loop. The rates and frame times are over the last full
+// second; Skipped and Dropped count from the start.
+type Stats struct {
+	FPS          int           // frames drawn a second
+	UPS          int           // simulation steps a second
+	FrameTime    time.Duration // average time a frame took, waiting excluded
+	MaxFrameTime time.Duration // longest frame
+	Skipped      int           // frames skipped because drawing fell behind
+	Dropped      int           // steps dropped past MaxSteps
+}
+
+// NewLoop creates a loop that draws every frame and simulates in steps of
+// step, on the real clock
+func NewLoop(frame, step time.Duration) *Loop {
+	return NewLoopClock(frame, step, SystemClock)
+}
+
+// NewLoopClock creates a loop timed and put to sleep by clock
+func NewLoopClock(frame, step time.Duration, clock Clock) *Loop {
+	l := &Loop{Frame: frame, Step: step, MaxSteps: DefaultMaxSteps, clock: clock}
+	l.Reset()
+	return l
+}
+
+// Reset starts the loop afresh, for after time spent outside it (in a
+// menu, on a game over screen) that shouldn't be caught up on
+func (l *Loop) Reset() {
+	now := l.clock.Now()
+	l.begun = now
+	l.next = now
+	l.accumulated = 0
+	l.window = now
+	l.frames, l.steps, l.work, l.worst = 0, 0, 0, 0
+}
+
+// Begin starts a frame and returns how many steps to simulate in it:
+// usually 0 or 1, more after a slow frame
+func (l *Loop) Begin() int {
+	now := l.clock.Now()
+	l.accumulated += now.Sub(l.begun)
+	l.begun = now
+	if l.Step <= 0 {
+		return 0
+	}
+
+	steps := int(l.accumulated / l.Step)
+	if l.MaxSteps > 0 && steps > l.MaxSteps {
+		l.dropped += steps - l.MaxSteps
+		steps = l.MaxSteps
+		l.accumulated %= l.Step
+	} else {
+		l.accumulated -= time.Duration(steps) * l.Step
+	}
+	l.steps += steps
+	return steps
+}
+
+// End finishes the frame and sleeps until the next one is due. A frame
+// that ran late isn't made up for: the frames it overlapped are skipped
+// and the loop carries on from now.
+func (l *Loop) End() {
+	now := l.clock.Now()
+	work := now.Sub(l.begun)
+	l.frames++
+	l.work += work
+	l.worst = max(l.worst, work)
+	if elapsed := now.Sub(l.window); elapsed >= time.Second {
+		l.stats = Stats{
+			FPS:          perSecond(l.frames, elapsed),
+			UPS:          perSecond(l.steps, elapsed),
+			FrameTime:    l.work / time.Duration(l.frames),
+			MaxFrameTime: l.worst,
+		}
+		l.window = now
+		l.frames, l.steps, l.work, l.worst = 0, 0, 0, 0
+	}
+
+	l.next = l.next.Add(l.Frame)
+	if wait := l.next.Sub(now); wait > 0 {
+		l.clock.Sleep(wait)
+		return
+	}
+	if l.Frame > 0 {
+		l.skipped += int(now.Sub(l.next) / l.Frame)
+	}
+	l.next = now
+}
+
+// perSecond is n events over elapsed as a rate, rounded
+func perSecond(n int, elapsed time.Duration) int {
+	return int((time.Duration(n)*time.Second + elapsed/2) / elapsed)
+}
+
+// Stats returns the loop's measurements
+func (l *Loop) Stats() Stats {
+	s := l.stats
+	s.Skipped, s.Dropped = l.skipped, l.dropped
+	return s
+}
+
+// String formats the stats for a log line
+func (s Stats) String() string {
+	return strconv.Itoa(s.FPS) + "fps " + strconv.Itoa(s.UPS) + "ups " +
+		strconv.Itoa(int(s.FrameTime/time.Millisecond)) + "ms (max " +
+		strconv.Itoa(int(s.MaxFrameTime/time.Millisecond)) + "ms) skipped " +
+		strconv.Itoa(s.Skipped) + " dropped " + strconv.Itoa(s.Dropped)
+}
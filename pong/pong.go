@@ -0,0 +1,667 @@
+// Package pong is the two-player Pong game for the 128x64 OLED
+package pong
+
+import (
+	"errors"
+	"image/color"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"tinygo.org/x/tinyfont"
+	"tinygo.org/x/tinyfont/freesans"
+
+	"gameoflife/console"
+	"gameoflife/display"
+	"gameoflife/input"
+	"gameoflife/life"
+	"gameoflife/log"
+	"gameoflife/pacing"
+	"gameoflife/power"
+	"gameoflife/settings"
+	"gameoflife/telemetry"
+	"gameoflife/ui"
+)
+
+const (
+	SCREEN_WIDTH  = 128
+	SCREEN_HEIGHT = 64
+
+	BALL_SIZE     = 3
+	PADDLE_WIDTH  = 3
+	PADDLE_HEIGHT = 12
+
+	INITIAL_BALL_SPEED = 5.0 // Starting speed (pixels per step)
+	SPEED_INCREMENT    = 0.4 // Speed increase per paddle hit
+	JUMP_SPEED         = 8   // How fast paddle rises when button pressed
+	FALL_SPEED         = 2   // How fast paddle falls when button released (gravity)
+)
+
+var pongLog = log.New("PONG")
+
+// The game is simulated in fixed steps, and drawn at its own rate: a slow
+// frame doesn't slow the ball down
+const (
+	physicsStep = 50 * time.Millisecond // 20 steps a second
+	frameTime   = 50 * time.Millisecond // 20 FPS
+)
+
+// aiLevels are the AI's paddle speed and how far the ball can be from the
+// paddle's centre before it moves, for each settings.PongDifficulties
+var aiLevels = []struct{ speed, deadZone int16 }{
+	{1, 4}, // Easy
+	{2, 2}, // Normal
+	{3, 1}, // Hard
+}
+
+// ═══════════════════════════════════════════════════════════════
+// GAME STRUCTURES
+// ═══════════════════════════════════════════════════════════════
+
+type Ball struct {
+	x     int16   // Current X position (center)
+	y     int16   // Current Y position (center)
+	dx    int16   // X velocity (-1 or 1)
+	dy    int16   // Y velocity (-1 or 1)
+	speed float32 // Current speed (increases with each hit)
+}
+
+type Paddle struct {
+	x      int16 // X position (left edge)
+	y      int16 // Y position (top edge)
+	width  int16
+	height int16
+}
+
+type WindParticle struct {
+	x    int16 // X position
+	y    int16 // Y position
+	life int   // Lifetime counter (0 = dead)
+}
+
+type GameState struct {
+	ball           Ball
+	player1        Paddle // Left paddle
+	player2        Paddle // Right paddle (AI or player 2)
+	score1         int
+	score2         int
+	gameRunning    bool
+	winner         int   // 0 = none, 1 = player1, 2 = player2
+	aiEnabled      bool  // AI mode vs 2-player
+	aiSpeed        int16 // from the difficulty setting
+	aiDeadZone     int16
+	winningScore   int
+	windParticles  []WindParticle // Visual effect when jumping
+	collisionCount int
+}
+
+// ═══════════════════════════════════════════════════════════════
+// COLLISION DETECTION
+// ═══════════════════════════════════════════════════════════════
+
+func detectPaddleCollision(ball *Ball, paddle *Paddle) (bool, float32) {
+	ballLeft := ball.x - BALL_SIZE/2
+	ballRight := ball.x + BALL_SIZE/2
+	ballTop := ball.y - BALL_SIZE/2
+	ballBottom := ball.y + BALL_SIZE/2
+
+	paddleLeft := paddle.x
+	paddleRight := paddle.x + paddle.width
+	paddleTop := paddle.y
+	paddleBottom := paddle.y + paddle.height
+
+	collision := ballRight >= paddleLeft &&
+		ballLeft <= paddleRight &&
+		ballBottom >= paddleTop &&
+		ballTop <= paddleBottom
+
+	if !collision {
+		return false, 0.0
+	}
+
+	hitY := float32(ball.y - paddleTop)
+	hitPosition := hitY / float32(paddle.height)
+
+	return true, hitPosition
+}
+
+func detectWallCollision(ball *Ball) bool {
+	ballTop := ball.y - BALL_SIZE/2
+	ballBottom := ball.y + BALL_SIZE/2
+	return ballTop <= 0 || ballBottom >= SCREEN_HEIGHT
+}
+
+func detectGoal(ball *Ball) (bool, int) {
+	if ball.x < 0 {
+		return true, 2
+	}
+	if ball.x > SCREEN_WIDTH {
+		return true, 1
+	}
+	return false, 0
+}
+
+// ═══════════════════════════════════════════════════════════════
+// GAME LOGIC
+// ═══════════════════════════════════════════════════════════════
+
+func newGame(aiEnabled bool, config settings.Settings) *GameState {
+	level := aiLevels[1]
+	if config.PongDifficulty >= 0 && config.PongDifficulty < len(aiLevels) {
+		level = aiLevels[config.PongDifficulty]
+	}
+	return &GameState{
+		ball: Ball{
+			x:     SCREEN_WIDTH / 2,
+			y:     SCREEN_HEIGHT / 2,
+			dx:    1,
+			dy:    1,
+			speed: INITIAL_BALL_SPEED,
+		},
+		player1: Paddle{
+			x:      5,
+			y:      SCREEN_HEIGHT/2 - PADDLE_HEIGHT/2,
+			width:  PADDLE_WIDTH,
+			height: PADDLE_HEIGHT,
+		},
+		player2: Paddle{
+			x:      SCREEN_WIDTH - 5 - PADDLE_WIDTH,
+			y:      SCREEN_HEIGHT/2 - PADDLE_HEIGHT/2,
+			width:  PADDLE_WIDTH,
+			height: PADDLE_HEIGHT,
+		},
+		score1:        0,
+		score2:        0,
+		gameRunning:   true,
+		winner:        0,
+		aiEnabled:     aiEnabled,
+		aiSpeed:       level.speed,
+		aiDeadZone:    level.deadZone,
+		winningScore:  max(config.PongWinningScore, 1),
+		windParticles: make([]WindParticle, 0, 10), // Pre-allocate for efficiency
+	}
+}
+
+func (g *GameState) resetBall() {
+	g.ball.x = SCREEN_WIDTH / 2
+	g.ball.y = SCREEN_HEIGHT / 2
+	if g.ball.dx > 0 {
+		g.ball.dx = -1
+	} else {
+		g.ball.dx = 1
+	}
+	g.ball.dy = 1
+	g.ball.speed = INITIAL_BALL_SPEED // Reset speed when ball resets (after scoring)
+}
+
+func (g *GameState) updateBall() {
+	// Move ball using its current speed
+	g.ball.x += int16(float32(g.ball.dx) * g.ball.speed)
+	g.ball.y += int16(float32(g.ball.dy) * g.ball.speed)
+
+	if detectWallCollision(&g.ball) {
+		g.ball.dy = -g.ball.dy
+		if g.ball.y < BALL_SIZE/2 {
+			g.ball.y = BALL_SIZE / 2
+		}
+		if g.ball.y > SCREEN_HEIGHT-BALL_SIZE/2 {
+			g.ball.y = SCREEN_HEIGHT - BALL_SIZE/2
+		}
+	}
+
+	// Player 1 paddle collision
+	if collision, hitPos := detectPaddleCollision(&g.ball, &g.player1); collision && g.ball.dx < 0 {
+		g.ball.dx = -g.ball.dx
+		g.ball.speed += SPEED_INCREMENT // Increase speed on hit!
+
+		if g.ball.speed > 6.5 { // cap increment speed at 6
+			g.ball.speed = 6.5
+		}
+
+		g.collisionCount += 1
+
+		pongLog.Debug("P1 hit! Ball speed:", g.ball.speed, "collisions:", g.collisionCount)
+		if hitPos < 0.33 {
+			g.ball.dy = -1
+		} else if hitPos > 0.66 {
+			g.ball.dy = 1
+		}
+		g.ball.x = g.player1.x + g.player1.width + BALL_SIZE/2
+	}
+
+	// Player 2 paddle collision
+	if collision, hitPos := detectPaddleCollision(&g.ball, &g.player2); collision && g.ball.dx > 0 {
+		g.ball.dx = -g.ball.dx
+		g.ball.speed += SPEED_INCREMENT // Increase speed on hit!
+		g.collisionCount += 1
+		pongLog.Debug("P2 hit! Ball speed:", g.ball.speed, "collisions:", g.collisionCount)
+
+		if hitPos < 0.33 {
+			g.ball.dy = -1
+		} else if hitPos > 0.66 {
+			g.ball.dy = 1
+		}
+		g.ball.x = g.player2.x - BALL_SIZE/2
+	}
+
+	// Check goals
+	if scored, player := detectGoal(&g.ball); scored {
+		if player == 1 {
+			g.score1++
+		} else {
+			g.score2++
+		}
+
+		if g.score1 >= g.winningScore {
+			g.winner = 1
+			g.gameRunning = false
+		} else if g.score2 >= g.winningScore {
+			g.winner = 2
+			g.gameRunning = false
+		} else {
+			g.resetBall()
+		}
+	}
+}
+
+func (g *GameState) movePaddle(paddle *Paddle, direction int16, speed int16) {
+	paddle.y += direction * speed
+	if paddle.y < 0 {
+		paddle.y = 0
+	}
+	if paddle.y > SCREEN_HEIGHT-paddle.height {
+		paddle.y = SCREEN_HEIGHT - paddle.height
+	}
+}
+
+// Simple AI that tracks ball
+func (g *GameState) updateAI() {
+	if !g.aiEnabled {
+		return
+	}
+
+	paddleCenter := g.player2.y + g.player2.height/2
+
+	// Add some delay/imperfection to make AI beatable
+	if g.ball.y > paddleCenter+g.aiDeadZone {
+		g.movePaddle(&g.player2, 1, g.aiSpeed)
+	} else if g.ball.y < paddleCenter-g.aiDeadZone {
+		g.movePaddle(&g.player2, -1, g.aiSpeed)
+	}
+}
+
+// ═══════════════════════════════════════════════════════════════
+// WIND PARTICLE EFFECTS (visual feedback when jumping)
+// ═══════════════════════════════════════════════════════════════
+
+// Spawn wind particles at the bottom of the paddle when jumping
+func (g *GameState) spawnWindParticles() {
+	// Create 4 particles at the bottom of player1 paddle with slight spread
+	paddleBottom := g.player1.y + g.player1.height
+	paddleCenterX := g.player1.x + g.player1.width/2
+
+	// Spawn 4 particles with horizontal spread
+	offsets := []int16{-1, 0, 1, 0} // Left, center, right, center again
+	for i, offset := range offsets {
+		particle := WindParticle{
+			x:    paddleCenterX + offset,    // Spread around paddle center
+			y:    paddleBottom + int16(i%2), // Slightly staggered vertically
+			life: 3,                         // Lasts for 15 frames (longer trail)
+		}
+		g.windParticles = append(g.windParticles, particle)
+	}
+}
+
+// Update wind particles (move down and fade)
+func (g *GameState) updateWindParticles() {
+	// Update each particle
+	for i := len(g.windParticles) - 1; i >= 0; i-- {
+		g.windParticles[i].life--
+		g.windParticles[i].y++ // Fall downward
+
+		// Remove dead particles
+		if g.windParticles[i].life <= 0 || g.windParticles[i].y >= SCREEN_HEIGHT {
+			// Remove particle by swapping with last and truncating
+			g.windParticles[i] = g.windParticles[len(g.windParticles)-1]
+			g.windParticles = g.windParticles[:len(g.windParticles)-1]
+		}
+	}
+}
+
+// ═══════════════════════════════════════════════════════════════
+// SIMULATION STEP
+// ═══════════════════════════════════════════════════════════════
+
+// step advances the game by one physics step. humanP2 means player 2 has
+// joined; otherwise the AI plays them.
+func (g *GameState) step(buttonP1Pressed, buttonP2Pressed, humanP2 bool) {
+	// PLAYER 1 - Flappy Bird mechanics: paddle always falls, button makes it rise
+	if buttonP1Pressed {
+		// Button pressed - JUMP UP (fast!)
+		g.movePaddle(&g.player1, -1, JUMP_SPEED)
+		g.spawnWindParticles() // Spawn particles on jump
+	} else {
+		// Button not pressed - FALL DOWN slowly (gravity)
+		g.movePaddle(&g.player1, 1, FALL_SPEED)
+	}
+
+	// PLAYER 2 - Can be controlled by button OR AI
+	if humanP2 {
+		// Human player 2 controls (Flappy Bird style)
+		if buttonP2Pressed {
+			// Button pressed - JUMP UP (fast!)
+			g.movePaddle(&g.player2, -1, JUMP_SPEED)
+			g.spawnWindParticles() // Spawn particles on jump
+		} else {
+			// Button not pressed - FALL DOWN slowly (gravity)
+			g.movePaddle(&g.player2, 1, FALL_SPEED)
+		}
+	} else {
+		// AI controls player 2
+		g.updateAI()
+	}
+
+	g.updateBall()
+	g.updateWindParticles() // Update wind particles
+}
+
+// ═══════════════════════════════════════════════════════════════
+// RENDERING
+// ═══════════════════════════════════════════════════════════════
+
+func (g *GameState) draw(display display.Display) {
+	display.ClearBuffer()
+
+	// Draw center line (dashed)
+	for y := int16(0); y < SCREEN_HEIGHT; y += 5 {
+		display.SetPixel(SCREEN_WIDTH/2, y, color.RGBA{255, 255, 255, 255})
+	}
+
+	// Draw ball
+	for dy := int16(-BALL_SIZE / 2); dy <= BALL_SIZE/2; dy++ {
+		for dx := int16(-BALL_SIZE / 2); dx <= BALL_SIZE/2; dx++ {
+			x := g.ball.x + dx
+			y := g.ball.y + dy
+			if x >= 0 && x < SCREEN_WIDTH && y >= 0 && y < SCREEN_HEIGHT {
+				display.SetPixel(x, y, color.RGBA{255, 255, 255, 255})
+			}
+		}
+	}
+
+	// Draw player 1 paddle
+	for dy := int16(0); dy < g.player1.height; dy++ {
+		for dx := int16(0); dx < g.player1.width; dx++ {
+			display.SetPixel(g.player1.x+dx, g.player1.y+dy, color.RGBA{255, 255, 255, 255})
+		}
+	}
+
+	// Draw player 2 paddle
+	for dy := int16(0); dy < g.player2.height; dy++ {
+		for dx := int16(0); dx < g.player2.width; dx++ {
+			display.SetPixel(g.player2.x+dx, g.player2.y+dy, color.RGBA{255, 255, 255, 255})
+		}
+	}
+
+	// Draw scores (using numbers instead of dots)
+	white := color.RGBA{255, 255, 255, 255}
+
+	// Draw P1 score (left side)
+	scoreText := strconv.Itoa(g.score1)
+	tinyfont.WriteLine(display, &freesans.BoldOblique9pt7b, 15, 12, scoreText, white)
+
+	// Draw P2 score (right side)
+	scoreText = strconv.Itoa(g.score2)
+	tinyfont.WriteLine(display, &freesans.Bold9pt7b, SCREEN_WIDTH-12-10*int16(len(scoreText)), 12, scoreText, white)
+
+	// Draw wind particles (small dots that trail behind paddle when jumping)
+	for _, particle := range g.windParticles {
+		// Draw single pixel for each particle (simple and fast)
+		if particle.x >= 0 && particle.x < SCREEN_WIDTH && particle.y >= 0 && particle.y < SCREEN_HEIGHT {
+			display.SetPixel(particle.x, particle.y, color.RGBA{255, 255, 255, 255})
+		}
+	}
+
+	display.Display()
+}
+
+func showWinner(display display.Display, winner int) {
+	display.ClearBuffer()
+
+	white := color.RGBA{255, 255, 255, 255}
+
+	if winner == 1 {
+		tinyfont.WriteLine(display, &freesans.Bold12pt7b, 10, 35, "P1 WINS!", white)
+	} else {
+		tinyfont.WriteLine(display, &freesans.Bold12pt7b, 10, 35, "P2 WINS!", white)
+	}
+
+	display.Display()
+}
+
+// ═══════════════════════════════════════════════════════════════
+// MAIN LOOP
+// ═══════════════════════════════════════════════════════════════
+
+// Run shows the splash screen and then plays Pong forever.
+// buttonP1 and buttonP2 are the players' buttons; player 2 is the AI
+// until their button is first pressed. The difficulty, winning score and
+// brightness come from store; holding both buttons through the splash
+// screen opens a menu to change them.
+//
+// With neither button pressed for a while, the panel dims, turns off and
+// the board goes to sleep, as the power settings say; sleep puts the
+// board into deep sleep (nil where there is none).
+//
+// On the console ports (the serial port, and the web server's where there
+// is one; nil ones are skipped), "pong ai on" hands player 2's paddle to
+// the AI and "pong ai off" to their button.
+func Run(display display.Display, buttonP1, buttonP2 input.Button, store *settings.Store, sleep func(), ports ...io.ReadWriter) {
+	config, err := store.Load()
+	if err != nil {
+		pongLog.Warn("Using default settings:", err)
+	}
+	config.ApplyDisplay(display)
+
+	// Show splash screen
+	display.ClearBuffer()
+	white := color.RGBA{255, 255, 255, 255}
+	tinyfont.WriteLine(display, &freesans.Bold18pt7b, 25, 30, "PONG", white)
+	tinyfont.WriteLine(display, &freesans.Regular9pt7b, 15, 50, "Press to Start", white)
+	display.Display()
+	time.Sleep(2 * time.Second)
+
+	if buttonP1.Pressed() && buttonP2.Pressed() {
+		runSettings(display, buttonP1, buttonP2, &config)
+		if err := store.Save(config); err != nil {
+			pongLog.Warn("Saving settings failed:", err)
+		}
+	}
+
+	// Start game with AI enabled (single player mode)
+	game := newGame(true, config)
+	pongLog.Info("Game started - AI mode")
+
+	frameCount := 0
+	lastP2ButtonState := false // Track if P2 is actively playing
+	loop := pacing.NewLoop(frameTime, physicsStep)
+	saver := power.NewManager(display, power.PolicyFor(config), uint8(config.Contrast))
+	saver.Sleep = sleep
+	sentAt := time.Now()
+	con := console.New(commands, ports...)
+
+	// Main game loop
+	for {
+		steps := loop.Begin()
+
+		// Check buttons - Flappy Bird style controls for both players
+		buttonP1Pressed := buttonP1.Pressed()
+		buttonP2Pressed := buttonP2.Pressed()
+		if buttonP1Pressed || buttonP2Pressed {
+			saver.Activity()
+		}
+		awake := saver.Update() < power.Blanked
+
+		// Serial commands
+		for req, ok := con.Next(); ok; req, ok = con.Next() {
+			saver.Activity()
+			var err error
+			switch req.Name {
+			case "pong":
+				var ai bool
+				if ai, err = parseAI(req); err == nil && ai == lastP2ButtonState {
+					lastP2ButtonState = !ai
+					pongLog.Info("Player 2 AI:", ai)
+				}
+			case "settings":
+				for _, a := range config.Assignments() {
+					con.Print(a)
+				}
+			case "set":
+				// Difficulty and winning score apply from the next game
+				if err = assign(req, &config); err == nil {
+					saver.SetContrast(uint8(config.Contrast))
+					saver.Policy = power.PolicyFor(config)
+					if err := store.Save(config); err != nil {
+						pongLog.Warn("Saving settings failed:", err)
+					}
+				}
+			}
+			con.Done(err)
+		}
+
+		// Update P2 playing state (if button pressed recently, P2 is playing)
+		if buttonP2Pressed {
+			if !lastP2ButtonState {
+				pongLog.Info("Player 2 joined! (2-player mode)")
+			}
+			lastP2ButtonState = true
+		}
+
+		// Update game state
+		if game.gameRunning {
+			// Run the physics steps due, with the buttons as they are now
+			for ; steps > 0 && game.gameRunning; steps-- {
+				game.step(buttonP1Pressed, buttonP2Pressed, lastP2ButtonState)
+			}
+
+			// Draw game, unless the panel is off
+			if awake {
+				game.draw(display)
+			}
+
+			frameCount++
+			if frameCount%90 == 0 { // changed from 60
+				pongLog.Info("Score:", game.score1, "-", game.score2, loop.Stats().String())
+			}
+			if telemetry.Enabled() && time.Since(sentAt) >= telemetry.Interval {
+				game.sendTelemetry("pong", loop.Stats().FPS, lastP2ButtonState)
+				sentAt = time.Now()
+			}
+		} else {
+			// Game over
+			pongLog.Info("Player", game.winner, "wins", game.score1, "-", game.score2)
+			if telemetry.Enabled() {
+				game.sendTelemetry("pong-over", loop.Stats().FPS, lastP2ButtonState)
+			}
+			showWinner(display, game.winner)
+			time.Sleep(3 * time.Second)
+
+			// Reset for new game
+			game = newGame(true, config)
+			lastP2ButtonState = false // Reset to AI mode for new game
+			pongLog.Info("New game started")
+			loop.Reset() // don't catch up on the winner screen
+		}
+
+		// Wait for the next frame
+		loop.End()
+	}
+}
+
+// sendTelemetry sends a record of kind with the score
+func (g *GameState) sendTelemetry(kind string, fps int, twoPlayer bool) {
+	p2 := "ai"
+	if twoPlayer {
+		p2 = "human"
+	}
+	telemetry.Send(kind,
+		telemetry.Int("s1", g.score1),
+		telemetry.Int("s2", g.score2),
+		telemetry.Int("win", g.winner),
+		telemetry.String("p2", p2),
+		telemetry.Int("fps", fps))
+}
+
+// commands are what Pong answers on the serial console
+var commands = []console.Command{
+	{Name: "pong", Usage: "ai on|off", Help: "player 2 is the AI, or their button"},
+	{Name: "settings", Help: "list the settings"},
+	{Name: "set", Usage: "<name>=<value>...", Help: "change and save settings"},
+}
+
+// assign carries out "set". The Game of Life's settings are kept in the
+// same store, so they are checked as it checks them.
+func assign(req console.Request, config *settings.Settings) error {
+	changed := *config
+	if err := changed.Assign(req.Args); err != nil {
+		return err
+	}
+	if err := life.CheckSettings(changed); err != nil {
+		return err
+	}
+	*config = changed
+	return nil
+}
+
+// parseAI reads "pong ai on|off"
+func parseAI(req console.Request) (bool, error) {
+	if len(req.Args) == 2 && strings.EqualFold(req.Args[0], "ai") {
+		switch strings.ToLower(req.Args[1]) {
+		case "on":
+			return true, nil
+		case "off":
+			return false, nil
+		}
+	}
+	return false, errors.New("pong: usage: pong ai on|off")
+}
+
+// runSettings shows the settings menu until Play is chosen. Player 1's
+// button moves down the list (holding it repeats) and player 2's selects;
+// holding player 2's leaves a value or the menu.
+func runSettings(display display.Display, buttonP1, buttonP2 input.Button, config *settings.Settings) {
+	pongLog.Info("Settings menu")
+
+	// Let go of both buttons first, or the held one reads as a press
+	for buttonP1.Pressed() || buttonP2.Pressed() {
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	noButton := input.ButtonFunc(func() bool { return false })
+	nav := ui.NewButtonsNav(noButton, buttonP1, buttonP2)
+	menu := &ui.Menu{
+		Title: "PONG SETTINGS",
+		Items: []ui.Item{
+			{Label: "Play", Exit: true},
+			{Label: "Difficulty", Value: &ui.Choice{Value: &config.PongDifficulty, Options: settings.PongDifficulties}},
+			{Label: "Winning score", Value: &ui.Slider{Value: &config.PongWinningScore, Min: 1, Max: 21, Step: 1}},
+			{Label: "Brightness", Value: &ui.Slider{Value: &config.Contrast, Min: 15, Max: 255, Step: 16}},
+		},
+	}
+
+	contrast := config.Contrast
+	loop := pacing.NewLoop(frameTime, 0) // nothing to simulate
+	for {
+		loop.Begin()
+		if config.Contrast != contrast {
+			contrast = config.Contrast
+			config.ApplyDisplay(display)
+		}
+		menu.Show(display)
+		if menu.Handle(nav.Nav()) != ui.MenuOpen {
+			return // Play, or back out of the menu
+		}
+		loop.End()
+	}
+}
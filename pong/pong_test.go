@@ -0,0 +1,90 @@
+package pong
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"tinygo.org/x/drivers/ssd1306"
+
+	"gameoflife/display"
+	"gameoflife/settings"
+	"gameoflife/ssd1306emu"
+)
+
+var pngDir = flag.String("png", "", "save the panels the tests draw as PNGs in this directory")
+
+// emulatedOLED is the real SSD1306 driver on an emulated 128x64 panel
+func emulatedOLED(t *testing.T) (*ssd1306.Device, *ssd1306emu.Device) {
+	t.Helper()
+	bus := ssd1306emu.NewBus()
+	oled := bus.Attach(0x3C, SCREEN_WIDTH, SCREEN_HEIGHT)
+	dev := ssd1306.NewI2C(bus)
+	dev.Configure(ssd1306.Config{Address: 0x3C, Width: SCREEN_WIDTH, Height: SCREEN_HEIGHT})
+	return dev, oled
+}
+
+// checkPanel compares the panel with a framebuffer drawn the same way,
+// byte for byte, and saves it as a PNG if asked to
+func checkPanel(t *testing.T, oled *ssd1306emu.Device, fb *display.Framebuffer, name string) {
+	t.Helper()
+	if !bytes.Equal(oled.RAM(), fb.Buffer()) {
+		t.Fatalf("%s: panel RAM differs from the framebuffer", name)
+	}
+	for y := int16(0); y < SCREEN_HEIGHT; y++ {
+		for x := int16(0); x < SCREEN_WIDTH; x++ {
+			if oled.Pixel(int(x), int(y)) != fb.GetPixel(x, y) {
+				t.Fatalf("%s: pixel (%d, %d) shows %v", name, x, y, oled.Pixel(int(x), int(y)))
+			}
+		}
+	}
+	var b bytes.Buffer
+	if err := oled.WritePNG(&b, 1); err != nil {
+		t.Fatal(err)
+	}
+	if *pngDir != "" {
+		if err := os.MkdirAll(*pngDir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(*pngDir, name+".png"), b.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDraw(t *testing.T) {
+	dev, oled := emulatedOLED(t)
+	g := newGame(true, settings.Defaults)
+	g.score1, g.score2 = 3, 10
+	for frame := 0; frame < 30; frame++ {
+		g.step(frame%4 == 0, false, false)
+		g.draw(dev)
+		fb := display.NewFramebuffer(SCREEN_WIDTH, SCREEN_HEIGHT)
+		g.draw(fb)
+		checkPanel(t, oled, fb, fmt.Sprintf("pong-%02d", frame))
+	}
+
+	// The ball and both paddles are on the panel
+	for _, p := range [][2]int16{
+		{g.ball.x, g.ball.y},
+		{g.player1.x, g.player1.y},
+		{g.player2.x + g.player2.width - 1, g.player2.y + g.player2.height - 1},
+	} {
+		if !oled.Pixel(int(p[0]), int(p[1])) {
+			t.Errorf("pixel (%d, %d) isn't lit", p[0], p[1])
+		}
+	}
+}
+
+func TestShowWinner(t *testing.T) {
+	for _, winner := range []int{1, 2} {
+		dev, oled := emulatedOLED(t)
+		showWinner(dev, winner)
+		fb := display.NewFramebuffer(SCREEN_WIDTH, SCREEN_HEIGHT)
+		showWinner(fb, winner)
+		checkPanel(t, oled, fb, fmt.Sprintf("winner-%d", winner))
+	}
+}
@@ -0,0 +1,166 @@
+// Package power saves the OLED and the battery when nobody is watching.
+//
+// A Manager follows the time since the last input: after a while the
+// panel dims, later it turns off, and in the end the board goes into deep
+// sleep until the button wakes it. Any input brings the panel back. A
+// Shift moves the picture around by a pixel now and then, so a Game of
+// Life left running for days doesn't wear the same pixels.
+package power
+
+import (
+	"time"
+
+	"gameoflife/display"
+	"gameoflife/input"
+	"gameoflife/log"
+	"gameoflife/settings"
+)
+
+var powerLog = log.New("POWER")
+
+// Stage is how far the power saving has gone
+type Stage int
+
+const (
+	Awake   Stage = iota
+	Dimmed        // panel at DimContrast
+	Blanked       // panel off
+	Asleep        // deep sleep (on the board; elsewhere the panel stays off)
+)
+
+var stageNames = []string{"awake", "dimmed", "blanked", "asleep"}
+
+// String names the stage for log output
+func (s Stage) String() string {
+	if s < 0 || int(s) >= len(stageNames) {
+		return "?"
+	}
+	return stageNames[s]
+}
+
+// Policy is when each stage starts, as time without input. Zero means
+// never.
+type Policy struct {
+	DimAfter, BlankAfter, SleepAfter time.Duration
+	DimContrast                      uint8 // contrast while dimmed
+}
+
+// DefaultDimContrast is the contrast of a dimmed panel: readable in a lit
+// room, a fraction of the current
+const DefaultDimContrast = 8
+
+// PolicyFor is the policy in the power settings
+func PolicyFor(s settings.Settings) Policy {
+	return Policy{
+		DimAfter:    time.Duration(s.DimAfter) * time.Minute,
+		BlankAfter:  time.Duration(s.BlankAfter) * time.Minute,
+		SleepAfter:  time.Duration(s.SleepAfter) * time.Minute,
+		DimContrast: DefaultDimContrast,
+	}
+}
+
+// due is the stage for idle time without input
+func (p Policy) due(idle time.Duration) Stage {
+	stage := Awake
+	if p.DimAfter > 0 && idle >= p.DimAfter {
+		stage = Dimmed
+	}
+	if p.BlankAfter > 0 && idle >= p.BlankAfter {
+		stage = Blanked
+	}
+	if p.SleepAfter > 0 && idle >= p.SleepAfter {
+		stage = Asleep
+	}
+	return stage
+}
+
+// Manager applies a Policy to a display. Call Activity on every input and
+// Update once a frame.
+type Manager struct {
+	Policy
+
+	// Sleep puts the board into deep sleep, from which it wakes by
+	// resetting. If it returns instead (a board that can only idle), that
+	// counts as input. Without one, Asleep leaves the panel off until the
+	// next input.
+	Sleep func()
+
+	display   display.Display
+	contrast  uint8 // when awake
+	clock     input.Clock
+	lastInput time.Time
+	stage     Stage
+}
+
+// NewManager creates a manager for d, whose contrast when awake is
+// contrast, using the real clock
+func NewManager(d display.Display, p Policy, contrast uint8) *Manager {
+	return NewManagerClock(d, p, contrast, input.SystemClock)
+}
+
+// NewManagerClock creates a manager timed with clock
+func NewManagerClock(d display.Display, p Policy, contrast uint8, clock input.Clock) *Manager {
+	return &Manager{Policy: p, display: d, contrast: contrast, clock: clock, lastInput: clock.Now()}
+}
+
+// Activity records input. It returns true if the panel was dimmed or off
+// and the input woke it, so the caller can drop that input instead of
+// acting on a press made to see the screen.
+func (m *Manager) Activity() bool {
+	m.lastInput = m.clock.Now()
+	woke := m.stage != Awake
+	m.enter(Awake)
+	return woke
+}
+
+// Update moves to the stage that is due and returns it. Drawing can be
+// skipped while the panel is off.
+func (m *Manager) Update() Stage {
+	m.enter(m.Policy.due(m.clock.Now().Sub(m.lastInput)))
+	return m.stage
+}
+
+// Stage returns the current stage
+func (m *Manager) Stage() Stage {
+	return m.stage
+}
+
+// SetContrast changes the contrast used while awake (the brightness
+// setting), and applies it unless the panel is dimmed or off
+func (m *Manager) SetContrast(contrast uint8) {
+	m.contrast = contrast
+	if m.stage == Awake {
+		display.SetContrast(m.display, contrast)
+	}
+}
+
+// enter changes the panel for stage, if it isn't there already
+func (m *Manager) enter(stage Stage) {
+	if stage == m.stage {
+		return
+	}
+	from := m.stage
+	m.stage = stage
+	powerLog.Info(from, "->", stage)
+
+	switch stage {
+	case Awake:
+		display.SetContrast(m.display, m.contrast)
+		if from >= Blanked {
+			display.SetPower(m.display, true)
+		}
+	case Dimmed:
+		display.SetContrast(m.display, min(m.DimContrast, m.contrast))
+		if from >= Blanked {
+			display.SetPower(m.display, true)
+		}
+	case Blanked:
+		display.SetPower(m.display, false)
+	case Asleep:
+		display.SetPower(m.display, false)
+		if m.Sleep != nil {
+			m.Sleep()
+			m.Activity()
+		}
+	}
+}
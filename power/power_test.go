@@ -0,0 +1,113 @@
+package power
+
+import (
+	"testing"
+	"time"
+
+	"gameoflife/display"
+	"gameoflife/input"
+)
+
+// panel is a framebuffer that takes the SSD1306 commands the manager
+// sends, keeping track of the power and contrast they set
+type panel struct {
+	*display.Framebuffer
+	on       bool
+	contrast uint8
+	param    bool // the next byte is the contrast
+}
+
+func newPanel() *panel {
+	return &panel{Framebuffer: display.NewFramebuffer(128, 64), on: true, contrast: 0xCF}
+}
+
+func (p *panel) Command(cmd uint8) {
+	switch {
+	case p.param:
+		p.contrast, p.param = cmd, false
+	case cmd == 0x81:
+		p.param = true
+	case cmd == 0xAE:
+		p.on = false
+	case cmd == 0xAF:
+		p.on = true
+	}
+}
+
+var testPolicy = Policy{DimAfter: time.Minute, BlankAfter: 2 * time.Minute, SleepAfter: 3 * time.Minute, DimContrast: 8}
+
+func TestManagerStages(t *testing.T) {
+	clock := input.NewScript()
+	p := newPanel()
+	m := NewManagerClock(p, testPolicy, 0xCF, clock)
+
+	steps := []struct {
+		at       time.Duration
+		stage    Stage
+		on       bool
+		contrast uint8
+	}{
+		{0, Awake, true, 0xCF},
+		{59 * time.Second, Awake, true, 0xCF},
+		{time.Minute, Dimmed, true, 8},
+		{2 * time.Minute, Blanked, false, 8},
+	}
+	for _, s := range steps {
+		clock.Advance(s.at - clock.Elapsed())
+		if got := m.Update(); got != s.stage || p.on != s.on || p.contrast != s.contrast {
+			t.Errorf("at %v: %v, on %v, contrast %d; want %v, on %v, contrast %d",
+				s.at, got, p.on, p.contrast, s.stage, s.on, s.contrast)
+		}
+	}
+	if !m.Activity() {
+		t.Error("input on a blank panel didn't wake it")
+	}
+	if m.Stage() != Awake || !p.on || p.contrast != 0xCF {
+		t.Errorf("after input: %v, on %v, contrast %d", m.Stage(), p.on, p.contrast)
+	}
+	if m.Activity() {
+		t.Error("input on an awake panel woke it")
+	}
+}
+
+func TestManagerAsleepWithoutSleep(t *testing.T) {
+	clock := input.NewScript()
+	p := newPanel()
+	m := NewManagerClock(p, testPolicy, 0xCF, clock)
+
+	// Asleep leaves the panel off, however long it stays idle
+	for clock.Elapsed() < time.Hour {
+		clock.Advance(time.Second)
+		m.Update()
+		if clock.Elapsed() >= testPolicy.SleepAfter && (m.Stage() != Asleep || p.on) {
+			t.Fatalf("at %v: %v, on %v; want asleep and off", clock.Elapsed(), m.Stage(), p.on)
+		}
+	}
+	if !m.Activity() || m.Stage() != Awake || !p.on || p.contrast != 0xCF {
+		t.Errorf("after input: %v, on %v, contrast %d", m.Stage(), p.on, p.contrast)
+	}
+}
+
+func TestManagerSleepReturns(t *testing.T) {
+	clock := input.NewScript()
+	p := newPanel()
+	m := NewManagerClock(p, testPolicy, 0xCF, clock)
+	sleeps := 0
+	m.Sleep = func() {
+		sleeps++
+		if p.on {
+			t.Error("slept with the panel on")
+		}
+		clock.Advance(10 * time.Minute) // idling until the button is pressed
+	}
+
+	clock.Advance(testPolicy.SleepAfter)
+	// Waking from an idle that returns counts as input
+	if stage := m.Update(); stage != Awake || !p.on || sleeps != 1 {
+		t.Errorf("after sleeping: %v, on %v, %d sleeps", stage, p.on, sleeps)
+	}
+	clock.Advance(time.Second)
+	if stage := m.Update(); stage != Awake || sleeps != 1 {
+		t.Errorf("a second after waking: %v, %d sleeps", stage, sleeps)
+	}
+}
@@ -0,0 +1,65 @@
+package power
+
+import (
+	"image/color"
+	"time"
+
+	"gameoflife/display"
+	"gameoflife/input"
+)
+
+// DefaultShiftInterval is how long the picture stays in one place
+const DefaultShiftInterval = 2 * time.Minute
+
+// shiftOrbit is the offsets a Shift goes round: a pixel square, so the
+// picture is never more than a pixel from where it was drawn
+var shiftOrbit = [][2]int16{{0, 0}, {1, 0}, {1, 1}, {0, 1}}
+
+// Shift is a Display drawn a pixel or so away from where its user draws,
+// moving every Interval (at the first ClearBuffer after it). Pixels
+// pushed off one edge come back on the opposite one, which on a wrapping
+// Game of Life board is seamless.
+type Shift struct {
+	Interval time.Duration
+
+	display display.Display
+	clock   input.Clock
+	movedAt time.Time
+	step    int
+}
+
+// NewShift creates a pixel shift over d, using the real clock
+func NewShift(d display.Display, interval time.Duration) *Shift {
+	return NewShiftClock(d, interval, input.SystemClock)
+}
+
+// NewShiftClock creates a pixel shift timed with clock
+func NewShiftClock(d display.Display, interval time.Duration, clock input.Clock) *Shift {
+	return &Shift{Interval: interval, display: d, clock: clock, movedAt: clock.Now()}
+}
+
+// ClearBuffer starts a frame, moving the picture on if it is time to
+func (s *Shift) ClearBuffer() {
+	if now := s.clock.Now(); s.Interval > 0 && now.Sub(s.movedAt) >= s.Interval {
+		s.step = (s.step + 1) % len(shiftOrbit)
+		s.movedAt = now
+	}
+	s.display.ClearBuffer()
+}
+
+// SetPixel sets the pixel at (x, y) plus the current offset
+func (s *Shift) SetPixel(x, y int16, c color.RGBA) {
+	w, h := s.display.Size()
+	off := shiftOrbit[s.step]
+	s.display.SetPixel((x+off[0])%w, (y+off[1])%h, c)
+}
+
+// Size returns the size of the display underneath
+func (s *Shift) Size() (x, y int16) {
+	return s.display.Size()
+}
+
+// Display flushes the display underneath
+func (s *Shift) Display() error {
+	return s.display.Display()
+}
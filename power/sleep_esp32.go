@@ -0,0 +1,98 @@
+//go:build tinygo && esp32
+
+package power
+
+import (
+	"errors"
+	"machine"
+	"runtime/volatile"
+	"unsafe"
+)
+
+// ESP32 RTC registers used for deep sleep (Technical Reference Manual,
+// chapters 4 and 31)
+const (
+	rtcCntlState0    = 0x3FF48018 // bit 31: enter sleep
+	rtcCntlWakeup    = 0x3FF48038 // bits 20-31: wakeup sources
+	rtcCntlIntClr    = 0x3FF48048
+	rtcCntlPWC       = 0x3FF48080 // RTC power domains
+	rtcCntlDigPWC    = 0x3FF48084 // digital power domains
+	rtcCntlExtWakeup = 0x3FF480A0 // bit 30: level ext0 wakes on
+	rtcIOExtWakeup0  = 0x3FF484BC // bits 27-31: RTC GPIO for ext0
+	rtcIOTouchPad0   = 0x3FF48494 // pad registers of the touch pins, 4 bytes apart
+	sleepEnable      = 1 << 31
+	wakeupExt0       = 1 << 0 // in the wakeup sources field
+	wakeupShift      = 20
+	wakeupLevelHigh  = 1 << 30
+	rtcPDEnable      = 1 << 20 // power the RTC peripherals down in sleep
+	digWrapPDEnable  = 1 << 31 // power the digital core down in sleep
+	intSleepWakeup   = 1 << 0
+	intSleepReject   = 1 << 1
+	padMuxSel        = 1 << 19 // pad routed to the RTC
+	padFunSel        = 3 << 17 // RTC function: 0 is RTC GPIO
+	padFunIE         = 1 << 13 // input enabled
+	padPullUp        = 1 << 27
+	padPullDown      = 1 << 28
+	extWakeup0Shift  = 27
+)
+
+// touchPads are the touch pins that can wake the ESP32 from deep sleep:
+// GPIO, touch pad number and RTC GPIO number. Other RTC GPIOs (25, 26, 32
+// and up) work too on the chip, but have differently laid out pad
+// registers and aren't supported here.
+var touchPads = []struct {
+	pin          machine.Pin
+	pad, rtcGPIO int
+}{
+	{machine.GPIO4, 0, 10},
+	{machine.GPIO0, 1, 11},
+	{machine.GPIO2, 2, 12},
+	{machine.GPIO15, 3, 13},
+	{machine.GPIO13, 4, 14},
+	{machine.GPIO12, 5, 15},
+	{machine.GPIO14, 6, 16},
+	{machine.GPIO27, 7, 17},
+}
+
+func reg(addr uintptr) *volatile.Register32 {
+	return (*volatile.Register32)(unsafe.Pointer(addr))
+}
+
+// DeepSleep powers the ESP32 down until wake is pulled low (a button to
+// GND; the pin gets the RTC's pull-up). Waking resets the chip, so the
+// firmware starts again from main. It only returns if wake can't wake
+// the ESP32 from deep sleep.
+//
+// Only RTC GPIOs can. GPIO18, where the button is, isn't one, so the
+// button has to be wired to a pin that is as well (see board.WakePin).
+func DeepSleep(wake machine.Pin) error {
+	pad, rtcGPIO := -1, 0
+	for _, p := range touchPads {
+		if p.pin == wake {
+			pad, rtcGPIO = p.pad, p.rtcGPIO
+		}
+	}
+	if pad < 0 {
+		return errors.New("power: pin can't wake the ESP32 from deep sleep")
+	}
+
+	// Hand the pin to the RTC as an input with its pull-up
+	r := reg(rtcIOTouchPad0 + uintptr(pad)*4)
+	r.ClearBits(padFunSel | padPullDown)
+	r.SetBits(padMuxSel | padFunIE | padPullUp)
+
+	// ext0: wake when that RTC GPIO goes low, and on nothing else
+	reg(rtcIOExtWakeup0).ReplaceBits(uint32(rtcGPIO), 0x1F, extWakeup0Shift)
+	reg(rtcCntlExtWakeup).ClearBits(wakeupLevelHigh)
+	reg(rtcCntlWakeup).ReplaceBits(wakeupExt0, 0xFFF, wakeupShift)
+
+	// ext0 needs the RTC peripherals powered; everything digital goes
+	reg(rtcCntlPWC).ClearBits(rtcPDEnable)
+	reg(rtcCntlDigPWC).SetBits(digWrapPDEnable)
+
+	reg(rtcCntlIntClr).Set(intSleepWakeup | intSleepReject)
+	reg(rtcCntlState0).SetBits(sleepEnable)
+	for {
+		// The chip powers down here; waking starts it from reset
+	}
+}
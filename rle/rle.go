@@ -0,0 +1,289 @@
+// Package rle reads and writes Game of Life patterns in the run length
+// encoded (RLE) format used by Golly and the LifeWiki.
+//
+// A pattern file looks like this (a glider):
+//
+//	#N glider
+//	x = 3, y = 3, rule = B3/S23
+//	bob$2bo$3o!
+package rle
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// DefaultRule is written to the header when a pattern has no rule of its own
+const DefaultRule = "B3/S23"
+
+// maxLineLength keeps encoded lines short enough for other Life programs
+const maxLineLength = 70
+
+// MaxSize is the largest width or height Decode takes. Patterns come from
+// the serial console and the network, so the header can't be trusted to
+// say how much memory to set aside; on the board, DecodeClip keeps only
+// what fits the grid.
+const MaxSize = 4096
+
+var (
+	errNoHeader = errors.New("rle: missing \"x = .., y = ..\" header")
+	errBadSize  = errors.New("rle: invalid pattern size")
+)
+
+// Board is anything that can report which of its cells are alive
+type Board interface {
+	Size() (width, height int)
+	Alive(x, y int) bool
+}
+
+// Pattern is a decoded RLE pattern
+type Pattern struct {
+	Name   string
+	Rule   string
+	Width  int
+	Height int
+	cells  [][]byte // [y], a bit per x, rows only as long as their last live cell
+}
+
+// Size returns the pattern's bounding box
+func (p *Pattern) Size() (int, int) {
+	return p.Width, p.Height
+}
+
+// Alive reports whether the cell at (x, y) is alive
+func (p *Pattern) Alive(x, y int) bool {
+	if x < 0 || y < 0 || y >= len(p.cells) || x/8 >= len(p.cells[y]) {
+		return false
+	}
+	return p.cells[y][x/8]>>(x%8)&1 == 1
+}
+
+// set brings the cell at (x, y) to life, growing the rows to hold it
+func (p *Pattern) set(x, y int) {
+	for len(p.cells) <= y {
+		p.cells = append(p.cells, nil)
+	}
+	row := p.cells[y]
+	for len(row) <= x/8 {
+		row = append(row, 0)
+	}
+	row[x/8] |= 1 << (x % 8)
+	p.cells[y] = row
+}
+
+// Encode writes the live cells of b as an RLE pattern.
+// The pattern is trimmed to the bounding box of the live cells.
+func Encode(w io.Writer, b Board, name string) error {
+	return EncodeRule(w, b, name, DefaultRule)
+}
+
+// EncodeRule is Encode for a board that runs under rule, such as "B36/S23"
+func EncodeRule(w io.Writer, b Board, name, rule string) error {
+	width, height := b.Size()
+
+	// Find the bounding box of the live cells
+	minX, minY, maxX, maxY := width, height, -1, -1
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if b.Alive(x, y) {
+				minX, maxX = min(minX, x), max(maxX, x)
+				minY, maxY = min(minY, y), max(maxY, y)
+			}
+		}
+	}
+	if maxX < 0 {
+		// Empty board - store a single dead cell
+		minX, minY, maxX, maxY = 0, 0, 0, 0
+	}
+
+	bw := bufio.NewWriter(w)
+	if name != "" {
+		fmt.Fprintf(bw, "#N %s\n", name)
+	}
+	fmt.Fprintf(bw, "x = %d, y = %d, rule = %s\n", maxX-minX+1, maxY-minY+1, rule)
+
+	enc := &lineWriter{w: bw}
+	blankRows := 0
+	for y := minY; y <= maxY; y++ {
+		if y > minY {
+			blankRows++
+		}
+
+		// Collect the runs for this row, dropping trailing dead cells
+		var runs []run
+		for x := minX; x <= maxX; x++ {
+			alive := b.Alive(x, y)
+			if len(runs) > 0 && runs[len(runs)-1].alive == alive {
+				runs[len(runs)-1].count++
+			} else {
+				runs = append(runs, run{alive: alive, count: 1})
+			}
+		}
+		if len(runs) > 0 && !runs[len(runs)-1].alive {
+			runs = runs[:len(runs)-1]
+		}
+		if len(runs) == 0 {
+			continue
+		}
+
+		if blankRows > 0 {
+			enc.token(blankRows, '$')
+			blankRows = 0
+		}
+		for _, r := range runs {
+			tag := byte('b')
+			if r.alive {
+				tag = 'o'
+			}
+			enc.token(r.count, tag)
+		}
+	}
+	enc.token(1, '!')
+	bw.WriteByte('\n')
+
+	return bw.Flush()
+}
+
+// run is a horizontal stretch of cells in the same state
+type run struct {
+	alive bool
+	count int
+}
+
+// lineWriter wraps encoded tokens so no line exceeds maxLineLength
+type lineWriter struct {
+	w      *bufio.Writer
+	column int
+}
+
+func (lw *lineWriter) token(count int, tag byte) {
+	tok := string(tag)
+	if count > 1 {
+		tok = strconv.Itoa(count) + tok
+	}
+	if lw.column+len(tok) > maxLineLength {
+		lw.w.WriteByte('\n')
+		lw.column = 0
+	}
+	lw.w.WriteString(tok)
+	lw.column += len(tok)
+}
+
+// Decode parses an RLE pattern
+func Decode(r io.Reader) (*Pattern, error) {
+	return DecodeClip(r, MaxSize, MaxSize)
+}
+
+// DecodeClip parses an RLE pattern, keeping only the middle width x height
+// of a larger one: what Grid.PlaceCells would place on a board that size.
+// The pattern's Width and Height are those of the part kept, so memory is
+// bounded by the board however large the header says the pattern is.
+func DecodeClip(r io.Reader, width, height int) (*Pattern, error) {
+	p := &Pattern{Rule: DefaultRule}
+	sc := bufio.NewScanner(r)
+
+	// Header: comment lines followed by "x = .., y = .."
+	haveHeader := false
+	for !haveHeader && sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "#N"):
+			p.Name = strings.TrimSpace(line[2:])
+		case strings.HasPrefix(line, "#"):
+			continue
+		default:
+			if err := p.parseHeader(line); err != nil {
+				return nil, err
+			}
+			haveHeader = true
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if !haveHeader {
+		return nil, errNoHeader
+	}
+
+	// The window kept, in the pattern's coordinates, centred as PlaceCells
+	// centres a pattern
+	left, top := max(0, (p.Width-width)/2), max(0, (p.Height-height)/2)
+	p.Width, p.Height = min(p.Width, width), min(p.Height, height)
+
+	// Body: runs of b (dead), o (alive), $ (end of row) until !. Cells
+	// outside the window are dropped, so counts are capped just past the
+	// largest size.
+	x, y, count := 0, 0, 0
+	for sc.Scan() {
+		for _, c := range sc.Text() {
+			switch {
+			case c >= '0' && c <= '9':
+				count = min(count*10+int(c-'0'), MaxSize+1)
+			case c == 'b' || c == '.':
+				x += max(count, 1)
+				count = 0
+			case c == '$':
+				y += max(count, 1)
+				x, count = 0, 0
+			case c == '!':
+				return p, nil
+			case c == ' ' || c == '\t' || c == '\r':
+			default:
+				// 'o' and any other state letter count as alive
+				n := max(count, 1)
+				if y >= top && y < top+p.Height {
+					for cx := max(x, left); cx < min(x+n, left+p.Width); cx++ {
+						p.set(cx-left, y-top)
+					}
+				}
+				x += n
+				count = 0
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	// A missing '!' is tolerated, the pattern just ends here
+	return p, nil
+}
+
+// parseHeader reads the "x = 3, y = 3, rule = B3/S23" line
+func (p *Pattern) parseHeader(line string) error {
+	for _, field := range strings.Split(line, ",") {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return fmt.Errorf("rle: malformed header field %q", field)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+
+		switch key {
+		case "x", "y":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return errBadSize
+			}
+			if n > MaxSize {
+				return fmt.Errorf("rle: pattern larger than %dx%d", MaxSize, MaxSize)
+			}
+			if key == "x" {
+				p.Width = n
+			} else {
+				p.Height = n
+			}
+		case "rule":
+			p.Rule = value
+		}
+	}
+	if p.Width == 0 && p.Height == 0 {
+		return errNoHeader
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package rle
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     string
+		width  int
+		height int
+		alive  []string // rows, 'o' for a live cell
+	}{
+		{"glider", "x = 3, y = 3\nbo$2bo$3o!\n", 3, 3, []string{".o.", "..o", "ooo"}},
+		{"runs past the edge are clipped", "x = 2, y = 1\n2000000000o!\n", 2, 1, []string{"oo"}},
+		{"rows past the edge are dropped", "x = 1, y = 1\n2000000000$o!\n", 1, 1, []string{"."}},
+		{"largest size", "x = 4096, y = 4096\no!\n", 4096, 4096, []string{"o."}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Decode(strings.NewReader(tt.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if w, h := p.Size(); w != tt.width || h != tt.height {
+				t.Errorf("size %dx%d, want %dx%d", w, h, tt.width, tt.height)
+			}
+			for y, row := range tt.alive {
+				for x, c := range row {
+					if got := p.Alive(x, y); got != (c == 'o') {
+						t.Errorf("Alive(%d, %d) = %v", x, y, got)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeTooLarge(t *testing.T) {
+	for _, header := range []string{
+		"x = 2000000000, y = 2000000000",
+		"x = 4097, y = 1",
+		"x = 1, y = 4097",
+	} {
+		t.Run(header, func(t *testing.T) {
+			if _, err := Decode(strings.NewReader(header + "\no!\n")); err == nil {
+				t.Error("decoded")
+			}
+		})
+	}
+}
+
+func TestDecodeClip(t *testing.T) {
+	// A 10x4 pattern with alternating cells in its middle rows: the 4x2
+	// from column 3 and row 1 is kept
+	in := "x = 10, y = 4\n10o$bobobobobo$obobobobob$10o!\n"
+	p, err := DecodeClip(strings.NewReader(in), 4, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w, h := p.Size(); w != 4 || h != 2 {
+		t.Fatalf("size %dx%d, want 4x2", w, h)
+	}
+	want := []string{"o.o.", ".o.o"}
+	for y, row := range want {
+		for x, c := range row {
+			if got := p.Alive(x, y); got != (c == 'o') {
+				t.Errorf("Alive(%d, %d) = %v", x, y, got)
+			}
+		}
+	}
+
+	// A pattern smaller than the window is kept whole
+	p, err = DecodeClip(strings.NewReader("x = 3, y = 3\nbo$2bo$3o!\n"), 128, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w, h := p.Size(); w != 3 || h != 3 || !p.Alive(1, 0) || !p.Alive(2, 2) {
+		t.Errorf("glider decoded as %dx%d", w, h)
+	}
+}
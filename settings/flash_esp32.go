@@ -0,0 +1,82 @@
+//go:build tinygo && esp32
+
+package settings
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// FlashOffset is the flash sector the settings live in: the last 4KB of
+// a 4MB module, far past the end of the firmware image
+const FlashOffset = 0x3FF000
+
+const (
+	flashSectorSize = 4096
+	flashReadSize   = 64 // enough for any record
+)
+
+// SPI flash routines in the ESP32 mask ROM. TinyGo runs the firmware
+// from RAM, so the flash can be erased and written while it runs.
+//
+//export SPIUnlock
+func spiUnlock() int32
+
+//export SPIEraseSector
+func spiEraseSector(sector uint32) int32
+
+//export SPIWrite
+func spiWrite(addr uint32, src *uint32, size int32) int32
+
+//export SPIRead
+func spiRead(addr uint32, dst *uint32, size int32) int32
+
+// Flash is Storage in one 4KB sector of the ESP32's SPI flash
+type Flash struct {
+	offset uint32
+}
+
+// NewFlash creates flash Storage at offset, which must be the start of a
+// sector the firmware doesn't occupy (see FlashOffset)
+func NewFlash(offset uint32) *Flash {
+	return &Flash{offset: offset &^ (flashSectorSize - 1)}
+}
+
+func (f *Flash) Read() ([]byte, error) {
+	var words [flashReadSize / 4]uint32
+	if rc := spiRead(f.offset, &words[0], flashReadSize); rc != 0 {
+		return nil, fmt.Errorf("settings: flash read at 0x%X failed (%d)", f.offset, rc)
+	}
+	b := make([]byte, flashReadSize)
+	for i, w := range words {
+		binary.LittleEndian.PutUint32(b[i*4:], w)
+	}
+	return b, nil
+}
+
+func (f *Flash) Write(b []byte) error {
+	if len(b) > flashReadSize {
+		return fmt.Errorf("settings: record of %d bytes is too big for flash", len(b))
+	}
+	// The ROM writes whole words; the padding stays erased (0xFF)
+	words := make([]uint32, (len(b)+3)/4)
+	padded := make([]byte, len(words)*4)
+	copy(padded, b)
+	for i := len(b); i < len(padded); i++ {
+		padded[i] = 0xFF
+	}
+	for i := range words {
+		words[i] = binary.LittleEndian.Uint32(padded[i*4:])
+	}
+
+	if rc := spiUnlock(); rc != 0 {
+		return fmt.Errorf("settings: flash unlock failed (%d)", rc)
+	}
+	if rc := spiEraseSector(f.offset / flashSectorSize); rc != 0 {
+		return fmt.Errorf("settings: flash erase at 0x%X failed (%d)", f.offset, rc)
+	}
+	if rc := spiWrite(f.offset, &words[0], int32(len(padded))); rc != 0 {
+		return fmt.Errorf("settings: flash write at 0x%X failed (%d)", f.offset, rc)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package settings
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// field is a setting as the console and the host tool name it
+type field struct {
+	name    string
+	integer func(*Settings) *int
+	boolean func(*Settings) *bool
+}
+
+var fields = []field{
+	{name: "pattern", integer: func(s *Settings) *int { return &s.Pattern }},
+	{name: "rule", integer: func(s *Settings) *int { return &s.Rule }},
+	{name: "speed", integer: func(s *Settings) *int { return &s.Speed }},
+	{name: "density", integer: func(s *Settings) *int { return &s.Density }},
+	{name: "wrap", boolean: func(s *Settings) *bool { return &s.Wrap }},
+	{name: "hud", integer: func(s *Settings) *int { return &s.HUD }},
+	{name: "contrast", integer: func(s *Settings) *int { return &s.Contrast }},
+	{name: "pong-difficulty", integer: func(s *Settings) *int { return &s.PongDifficulty }},
+	{name: "pong-score", integer: func(s *Settings) *int { return &s.PongWinningScore }},
+	{name: "dim-after", integer: func(s *Settings) *int { return &s.DimAfter }},
+	{name: "blank-after", integer: func(s *Settings) *int { return &s.BlankAfter }},
+	{name: "sleep-after", integer: func(s *Settings) *int { return &s.SleepAfter }},
+	{name: "pixel-shift", boolean: func(s *Settings) *bool { return &s.PixelShift }},
+}
+
+// Names lists the settings by the names Get and Set take, in order
+func Names() []string {
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.name
+	}
+	return names
+}
+
+func findField(name string) (field, error) {
+	for _, f := range fields {
+		if f.name == strings.ToLower(name) {
+			return f, nil
+		}
+	}
+	return field{}, fmt.Errorf("settings: no setting called %q", name)
+}
+
+// Get returns the setting called name as text: a number, or on or off
+func (s Settings) Get(name string) (string, error) {
+	f, err := findField(name)
+	if err != nil {
+		return "", err
+	}
+	if f.boolean != nil {
+		if *f.boolean(&s) {
+			return "on", nil
+		}
+		return "off", nil
+	}
+	return strconv.Itoa(*f.integer(&s)), nil
+}
+
+// Set changes the setting called name to value, as Get writes it (true,
+// false, 1 and 0 are taken for on and off too). s is only changed if the
+// new value is in range.
+func (s *Settings) Set(name, value string) error {
+	f, err := findField(name)
+	if err != nil {
+		return err
+	}
+	changed := *s
+	if f.boolean != nil {
+		switch strings.ToLower(value) {
+		case "on", "true", "1":
+			*f.boolean(&changed) = true
+		case "off", "false", "0":
+			*f.boolean(&changed) = false
+		default:
+			return fmt.Errorf("settings: %s is on or off, not %q", f.name, value)
+		}
+	} else {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("settings: %s is a number, not %q", f.name, value)
+		}
+		*f.integer(&changed) = n
+	}
+	if err := changed.Validate(); err != nil {
+		return err
+	}
+	*s = changed
+	return nil
+}
+
+// Assignments lists every setting as "name=value"
+func (s Settings) Assignments() []string {
+	list := make([]string, len(fields))
+	for i, f := range fields {
+		v, _ := s.Get(f.name)
+		list[i] = f.name + "=" + v
+	}
+	return list
+}
+
+// Assign carries out "name=value" assignments, as Assignments lists them.
+// s is only changed if all of them are valid.
+func (s *Settings) Assign(assignments []string) error {
+	changed := *s
+	for _, a := range assignments {
+		name, value, ok := strings.Cut(a, "=")
+		if !ok {
+			return fmt.Errorf("settings: %q is not name=value", a)
+		}
+		if err := changed.Set(name, value); err != nil {
+			return err
+		}
+	}
+	*s = changed
+	return nil
+}
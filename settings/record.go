@@ -0,0 +1,56 @@
+package settings
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+)
+
+// A record as stored:
+//
+//	0  "GOLS"            magic
+//	4  version           layout of the payload
+//	5  length            payload bytes
+//	6  payload
+//	.. CRC-32 (IEEE)     of everything before it, little endian
+//
+// Erased flash reads as 0xFF, which never matches the magic.
+const (
+	magic      = "GOLS"
+	headerSize = 6
+	crcSize    = 4
+)
+
+// Errors from Decode. ErrNoRecord is the usual state of a new board.
+var (
+	ErrNoRecord = errors.New("settings: no record stored")
+	ErrChecksum = errors.New("settings: record checksum mismatch")
+)
+
+// Encode wraps a payload in a record with its version and checksum
+func Encode(version uint8, payload []byte) []byte {
+	b := make([]byte, 0, headerSize+len(payload)+crcSize)
+	b = append(b, magic...)
+	b = append(b, version, uint8(len(payload)))
+	b = append(b, payload...)
+	return binary.LittleEndian.AppendUint32(b, crc32.ChecksumIEEE(b))
+}
+
+// Decode checks a record read back from storage and returns its version
+// and payload. Bytes after the record (the rest of a flash sector) are
+// ignored.
+func Decode(b []byte) (version uint8, payload []byte, err error) {
+	if len(b) < headerSize || string(b[:len(magic)]) != magic {
+		return 0, nil, ErrNoRecord
+	}
+	version, n := b[4], int(b[5])
+	end := headerSize + n
+	if len(b) < end+crcSize {
+		return 0, nil, fmt.Errorf("settings: record truncated at %d bytes", len(b))
+	}
+	if crc32.ChecksumIEEE(b[:end]) != binary.LittleEndian.Uint32(b[end:]) {
+		return 0, nil, ErrChecksum
+	}
+	return version, b[headerSize:end], nil
+}
@@ -0,0 +1,167 @@
+// Package settings keeps the firmware's options across reboots.
+//
+// Both firmwares share one Settings record, stored in a reserved sector
+// of the ESP32's flash (Flash), in a file for the desktop simulator (File)
+// or in memory (Memory). The record carries a version and a checksum; if
+// it is missing or damaged, or holds a setting out of range, Load falls
+// back to Defaults.
+package settings
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"gameoflife/display"
+)
+
+// Settings are the options that survive a reboot
+type Settings struct {
+	// Game of Life
+	Pattern int  // index of the last pattern chosen
+	Rule    int  // index into life.Rules
+	Speed   int  // milliseconds between generations
+	Density int  // percent of cells alive in the RANDOM pattern
+	Wrap    bool // cells on opposite edges are neighbours
+	HUD     int  // a ui.HUDMode: off, status strip or banner
+
+	// Display
+	Contrast int // SSD1306 contrast, 1-255 (higher is brighter)
+
+	// Pong
+	PongDifficulty   int // how good the AI is: 0 easy, 1 normal, 2 hard
+	PongWinningScore int // points needed to win
+
+	// Power saving: minutes without input before each step, 0 for never
+	DimAfter   int  // the panel dims
+	BlankAfter int  // the panel turns off
+	SleepAfter int  // the ESP32 goes into deep sleep
+	PixelShift bool // the Game of Life moves a pixel now and then
+}
+
+// Defaults are the settings on a new board, or when the stored ones can't
+// be read
+var Defaults = Settings{
+	Pattern:          0,
+	Rule:             0,
+	Speed:            100,
+	Density:          30,
+	Wrap:             true,
+	HUD:              0,
+	Contrast:         255,
+	PongDifficulty:   1,
+	PongWinningScore: 5,
+	DimAfter:         2,
+	BlankAfter:       10,
+	SleepAfter:       60,
+	PixelShift:       true,
+}
+
+// Pong difficulties, for PongDifficulty
+var PongDifficulties = []string{"Easy", "Normal", "Hard"}
+
+// Version is the layout MarshalBinary writes. Fields are only ever added
+// at the end, in the reserved bytes or after them, so a record from a
+// newer firmware still decodes.
+const Version = 2
+
+// Payload lengths: version 1 ends at the Pong settings, version 2 adds
+// power saving
+const (
+	sizeV1 = 12
+	size   = 16
+)
+
+// MarshalBinary encodes the settings in the current Version's layout
+func (s Settings) MarshalBinary() ([]byte, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	b := make([]byte, size)
+	b[0] = uint8(s.Pattern)
+	b[1] = uint8(s.Rule)
+	binary.LittleEndian.PutUint16(b[2:], uint16(s.Speed))
+	b[4] = uint8(s.Density)
+	if s.Wrap {
+		b[5] = 1
+	}
+	b[6] = uint8(s.HUD)
+	b[7] = uint8(s.Contrast)
+	b[8] = uint8(s.PongDifficulty)
+	b[9] = uint8(s.PongWinningScore)
+	// b[10:12] reserved
+	b[12] = uint8(s.DimAfter)
+	b[13] = uint8(s.BlankAfter)
+	b[14] = uint8(s.SleepAfter)
+	if s.PixelShift {
+		b[15] = 1
+	}
+	return b, nil
+}
+
+// UnmarshalBinary decodes settings written by MarshalBinary, of this
+// version or an older one; settings an older record doesn't have keep
+// their Defaults. s is only changed if all of them are valid.
+func (s *Settings) UnmarshalBinary(b []byte) error {
+	if len(b) < sizeV1 {
+		return fmt.Errorf("settings: record is %d bytes, want at least %d", len(b), sizeV1)
+	}
+	d := Defaults
+	d.Pattern = int(b[0])
+	d.Rule = int(b[1])
+	d.Speed = int(binary.LittleEndian.Uint16(b[2:]))
+	d.Density = int(b[4])
+	d.Wrap = b[5] != 0
+	d.HUD = int(b[6])
+	d.Contrast = int(b[7])
+	d.PongDifficulty = int(b[8])
+	d.PongWinningScore = int(b[9])
+	if len(b) >= size {
+		d.DimAfter = int(b[12])
+		d.BlankAfter = int(b[13])
+		d.SleepAfter = int(b[14])
+		d.PixelShift = b[15] != 0
+	}
+	if err := d.Validate(); err != nil {
+		return err
+	}
+	*s = d
+	return nil
+}
+
+// Validate checks that every setting is in range. Indexes into lists
+// owned by other packages (Pattern, Rule, HUD) are only checked for size;
+// their users bounds-check them.
+func (s Settings) Validate() error {
+	switch {
+	case s.Pattern < 0 || s.Pattern > 255:
+		return fmt.Errorf("settings: pattern %d out of range", s.Pattern)
+	case s.Rule < 0 || s.Rule > 255:
+		return fmt.Errorf("settings: rule %d out of range", s.Rule)
+	case s.Speed < 10 || s.Speed > 5000:
+		return fmt.Errorf("settings: speed %dms out of range", s.Speed)
+	case s.Density < 0 || s.Density > 100:
+		return fmt.Errorf("settings: density %d%% out of range", s.Density)
+	case s.HUD < 0 || s.HUD > 255:
+		return fmt.Errorf("settings: HUD mode %d out of range", s.HUD)
+	case s.Contrast < 1 || s.Contrast > 255:
+		return fmt.Errorf("settings: contrast %d out of range", s.Contrast)
+	case s.PongDifficulty < 0 || s.PongDifficulty >= len(PongDifficulties):
+		return fmt.Errorf("settings: pong difficulty %d out of range", s.PongDifficulty)
+	case s.PongWinningScore < 1 || s.PongWinningScore > 99:
+		return fmt.Errorf("settings: winning score %d out of range", s.PongWinningScore)
+	case s.DimAfter < 0 || s.DimAfter > 255:
+		return fmt.Errorf("settings: dim after %d minutes out of range", s.DimAfter)
+	case s.BlankAfter < 0 || s.BlankAfter > 255:
+		return fmt.Errorf("settings: blank after %d minutes out of range", s.BlankAfter)
+	case s.SleepAfter < 0 || s.SleepAfter > 255:
+		return fmt.Errorf("settings: sleep after %d minutes out of range", s.SleepAfter)
+	}
+	return nil
+}
+
+// ApplyDisplay sets the display options (the contrast) on d
+func (s Settings) ApplyDisplay(d display.Display) {
+	if s.Contrast >= 1 && s.Contrast <= 255 {
+		display.SetContrast(d, uint8(s.Contrast))
+	}
+}
@@ -0,0 +1,107 @@
+package settings
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Storage is somewhere to keep one record
+type Storage interface {
+	// Read returns what is stored, which may run on past the record (a
+	// whole flash sector, say). Empty storage isn't an error.
+	Read() ([]byte, error)
+	// Write replaces what is stored
+	Write(b []byte) error
+}
+
+// Store loads and saves Settings on a Storage
+type Store struct {
+	storage Storage
+	saved   []byte // the record last read or written, to skip rewriting it
+}
+
+// NewStore creates a Store on storage
+func NewStore(storage Storage) *Store {
+	return &Store{storage: storage}
+}
+
+// Load reads the stored settings. If there are none, or they can't be
+// used, it returns Defaults along with the reason.
+func (st *Store) Load() (Settings, error) {
+	b, err := st.storage.Read()
+	if err != nil {
+		return Defaults, err
+	}
+	version, payload, err := Decode(b)
+	if err != nil {
+		return Defaults, err
+	}
+	if version < 1 {
+		return Defaults, fmt.Errorf("settings: unknown record version %d", version)
+	}
+	s := Defaults
+	if err := s.UnmarshalBinary(payload); err != nil {
+		return Defaults, err
+	}
+	st.saved = Encode(version, payload)
+	return s, nil
+}
+
+// Save stores s, unless it is what is stored already: flash sectors wear
+// out after so many erases, so unchanged settings are never rewritten.
+func (st *Store) Save(s Settings) error {
+	payload, err := s.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	record := Encode(Version, payload)
+	if bytes.Equal(record, st.saved) {
+		return nil
+	}
+	if err := st.storage.Write(record); err != nil {
+		return err
+	}
+	st.saved = record
+	return nil
+}
+
+// Memory is Storage in RAM, which is lost when the program ends
+type Memory struct {
+	mu   sync.Mutex
+	data []byte
+}
+
+func (m *Memory) Read() ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]byte(nil), m.data...), nil
+}
+
+func (m *Memory) Write(b []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data = append(m.data[:0], b...)
+	return nil
+}
+
+// File is Storage in a file, for the desktop simulator
+type File string
+
+func (f File) Read() ([]byte, error) {
+	b, err := os.ReadFile(string(f))
+	if os.IsNotExist(err) {
+		return nil, nil // nothing saved yet
+	}
+	return b, err
+}
+
+func (f File) Write(b []byte) error {
+	// Written to the side and renamed, so a crash can't leave half a record
+	tmp := string(f) + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, string(f))
+}
@@ -0,0 +1,317 @@
+// Desktop simulator for the OLED firmware.
+//
+// Runs the same life.Run / pong.Run loops that the ESP32 runs, but draws the
+// 128x64 display as a panel in the terminal and reads the buttons from the
+// keyboard or mouse, so the menu, Game of Life and Pong can be worked on
+// without reflashing:
+//
+//	go run ./simulator              # Game of Life firmware
+//	go run ./simulator -game pong   # Pong firmware
+//
+// Buttons:
+//
+//	GPIO18  space or a, or left mouse button
+//	GPIO19  enter or l, or right mouse button (Pong player 2)
+//	i       the info gesture for the chosen controls (shows the HUD)
+//	q       quit
+//
+// -controls picks the Game of Life controls, as the board build tags do:
+// "button" (GPIO18 only), "encoder" (arrow keys or j/k turn the knob,
+// GPIO18 is its push switch) or "buttons" (arrow keys or j/k are up and
+// down, GPIO18 is select).
+//
+// -panels 2 runs the Game of Life across two panels side by side, one
+// 256x64 universe, as the board's dual and tca9548a builds do.
+//
+// Settings live in memory and are forgotten on exit, unless -settings
+// names a file to keep them in (as the board keeps them in flash).
+//
+// The firmware's own timing (its time.Sleep calls) is untouched, so the
+// frame rate and click timings are the same as on the board. Its log lines
+// go to stderr; redirect them (2>sim.log) to keep them off the panel.
+// -log sets the lowest level logged (debug, info, warn, error or off) and
+// -telemetry json or cbor adds the telemetry records to them, as the
+// board's debug and telemetry builds do.
+//
+// -serial /tmp/gol-serial makes a pseudo-terminal stand in for the
+// board's USB serial port and links it at that path: a serial monitor or
+// the host tool opens it as it would /dev/ttyUSB0, sends console commands
+// and reads the log and telemetry, which then go there instead of stderr.
+//
+// -http :8080 serves the web endpoints (see package web): the dashboard
+// at http://localhost:8080/ shows the panel live, with buttons for the
+// commands and GPIO18/GPIO19, and curl --data-binary @glider.rle
+// localhost:8080/pattern plays a pattern. -token makes changes need a
+// token, and -firmware names a file to keep uploaded firmware images in;
+// without it they are refused.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"gameoflife/display"
+	"gameoflife/input"
+	"gameoflife/life"
+	"gameoflife/log"
+	"gameoflife/pong"
+	"gameoflife/settings"
+	"gameoflife/telemetry"
+	"gameoflife/terminal"
+	"gameoflife/ui"
+	"gameoflife/web"
+)
+
+func main() {
+	game := flag.String("game", "life", "firmware to run: life or pong")
+	controls := flag.String("controls", "button", "Game of Life controls: button, encoder or buttons")
+	rendererName := flag.String("renderer", terminal.HalfBlock.Name,
+		"how pixels are drawn: full, half-block or braille")
+	settingsFile := flag.String("settings", "", "file to keep the settings in between runs")
+	panels := flag.Int("panels", 1, "Game of Life panels tiled side by side (1-4)")
+	logLevel := flag.String("log", "info", "lowest level logged: debug, info, warn, error or off")
+	telemetryFormat := flag.String("telemetry", "off", "telemetry records in the log: off, json or cbor")
+	serialLink := flag.String("serial", "", "path to link a pseudo-terminal serial port at, for the console")
+	httpAddr := flag.String("http", "", "address to serve the web endpoints on, such as :8080")
+	token := flag.String("token", "", "token the web endpoints need for changes")
+	firmware := flag.String("firmware", "", "file to keep firmware images uploaded over the web in")
+	flag.Parse()
+
+	renderer, ok := rendererByName(*rendererName)
+	if !ok {
+		fmt.Fprintln(os.Stderr, "unknown renderer:", *rendererName)
+		os.Exit(2)
+	}
+	if *game != "life" && *game != "pong" {
+		fmt.Fprintln(os.Stderr, "unknown game:", *game)
+		os.Exit(2)
+	}
+	if *controls != "button" && *controls != "encoder" && *controls != "buttons" {
+		fmt.Fprintln(os.Stderr, "unknown controls:", *controls)
+		os.Exit(2)
+	}
+	level, err := log.ParseLevel(*logLevel)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	log.SetLevel(level)
+	format, err := telemetry.ParseFormat(*telemetryFormat)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	telemetry.SetFormat(format)
+	if *panels < 1 || *panels > 4 || *game == "pong" {
+		*panels = 1 // Pong is one panel wide
+	}
+
+	// The serial port, if there is one: console, log and telemetry
+	var serial io.ReadWriter
+	closeSerial := func() {}
+	if *serialLink != "" {
+		pty, err := openSerial(*serialLink)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "serial port:", err)
+			os.Exit(1)
+		}
+		fmt.Fprintln(os.Stderr, "Serial port:", pty.Name, "at", *serialLink)
+		log.SetOutput(pty)
+		telemetry.SetOutput(pty)
+		serial = pty
+		closeSerial = func() {
+			os.Remove(*serialLink)
+			pty.Close()
+		}
+	}
+
+	// The web server, if there is one, takes commands on its own port.
+	// It starts serving once the panel and buttons are set up.
+	var server *web.Server
+	var webPort io.ReadWriter
+	var ln net.Listener
+	if *httpAddr != "" {
+		ln, err = net.Listen("tcp", *httpAddr)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "web server:", err)
+			closeSerial()
+			os.Exit(1)
+		}
+		server = web.NewServer()
+		server.Game = *game
+		server.Token = *token
+		if *firmware != "" {
+			server.Updater = &web.FileUpdater{Path: *firmware}
+		}
+		webPort = server.Port
+		fmt.Fprintln(os.Stderr, "Web server: http://"+ln.Addr().String())
+	}
+
+	restore, err := terminal.MakeRaw()
+	if err != nil {
+		fmt.Println("Keyboard/mouse input unavailable:", err)
+		restore = func() {}
+	}
+	terminal.EnableMouse(os.Stdout)
+
+	panel := terminal.NewPanel(os.Stdout, 128*int16(*panels), 64)
+	panel.Renderer = renderer
+	panel.Caption = "GPIO18: space/a/left click   GPIO19: enter/l/right click   i: HUD   q: quit"
+	cleanup := func() {
+		panel.Close()
+		terminal.DisableMouse(os.Stdout)
+		restore()
+		closeSerial()
+	}
+
+	// Ctrl+C must still put the terminal back the way we found it
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		cleanup()
+		os.Exit(0)
+	}()
+
+	var storage settings.Storage = &settings.Memory{}
+	if *settingsFile != "" {
+		storage = settings.File(*settingsFile)
+	}
+	store := settings.NewStore(storage)
+
+	// Button wiring: GPIO18 is the only button on the Life board and
+	// player 1 in Pong, GPIO19 is Pong's player 2
+	var gpio18, gpio19 input.Key
+
+	// The encoder, or the up/down buttons, when those controls are used
+	var knob input.KeyEncoder
+	var up, down input.Key
+	var nav ui.Navigator
+	switch *controls {
+	case "encoder":
+		nav = ui.NewEncoderNav(&knob, &gpio18)
+		panel.Caption = "Turn: arrows/j/k   Push: space/a/left click   i: HUD   q: quit"
+	case "buttons":
+		nav = ui.NewButtonsNav(&up, &down, &gpio18)
+		panel.Caption = "Up/down: arrows/j/k   Select: space/a/left click   i: HUD   q: quit"
+	default:
+		nav = ui.NewButtonNav(&gpio18)
+	}
+	upDown := func(dir int) {
+		knob.Turn(dir)
+		if dir < 0 {
+			up.Tap()
+		} else {
+			down.Tap()
+		}
+	}
+	// info performs the info gesture, which can't be typed directly:
+	// a terminal doesn't report two keys held at once
+	info := func() {
+		switch *controls {
+		case "encoder":
+			gpio18.Tap() // turn while pushed in
+			knob.Turn(1)
+		case "buttons":
+			up.Tap() // up and down together
+			down.Tap()
+		default:
+			gpio18.Tap() // click, then hold
+			time.Sleep(2 * input.KeyHold)
+			gpio18.SetHeld(true)
+			time.Sleep(ui.DefaultGestures.LongPress + 200*time.Millisecond)
+			gpio18.SetHeld(false)
+		}
+	}
+
+	events := make(chan terminal.Event, 64)
+	go terminal.ReadEvents(os.Stdin, events)
+	go func() {
+		for ev := range events {
+			switch ev.Kind {
+			case terminal.KeyPress:
+				switch ev.Key {
+				case ' ', 'a':
+					gpio18.Tap()
+				case '\n', '\r', 'l':
+					gpio19.Tap()
+				case terminal.KeyUp, terminal.KeyLeft, 'k':
+					upDown(-1)
+				case terminal.KeyDown, terminal.KeyRight, 'j':
+					upDown(1)
+				case 'i':
+					go info()
+				case 'q':
+					cleanup()
+					os.Exit(0)
+				}
+			case terminal.MousePress, terminal.MouseRelease:
+				down := ev.Kind == terminal.MousePress
+				switch ev.Button {
+				case terminal.ButtonLeft:
+					gpio18.SetHeld(down)
+				case terminal.ButtonRight:
+					gpio19.SetHeld(down)
+				}
+			}
+		}
+	}()
+
+	// The dashboard shows what the panel does and presses the same keys
+	var screen display.Display = panel
+	if server != nil {
+		server.Mirror = display.NewMirror(panel)
+		screen = server.Mirror
+		server.Buttons = []web.Button{{Name: "GPIO18", Key: &gpio18}}
+		switch {
+		case *game == "pong":
+			server.Buttons = append(server.Buttons, web.Button{Name: "GPIO19", Key: &gpio19})
+		case *controls == "buttons":
+			server.Buttons = append(server.Buttons, web.Button{Name: "Up", Key: &up}, web.Button{Name: "Down", Key: &down})
+		}
+		go http.Serve(ln, server.Handler())
+	}
+
+	// The firmware loops forever; q or Ctrl+C ends the program
+	if *game == "pong" {
+		pong.Run(screen, &gpio18, &gpio19, store, nil, serial, webPort)
+	} else {
+		life.Run(screen, nav, store, nil, serial, webPort)
+	}
+}
+
+// openSerial opens a pseudo-terminal and links it at link, replacing an
+// old link there (but not anything else)
+func openSerial(link string) (*terminal.PTY, error) {
+	if fi, err := os.Lstat(link); err == nil {
+		if fi.Mode()&os.ModeSymlink == 0 {
+			return nil, fmt.Errorf("%s exists and isn't a link", link)
+		}
+		os.Remove(link)
+	}
+	pty, err := terminal.OpenPTY()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Symlink(pty.Name, link); err != nil {
+		pty.Close()
+		return nil, err
+	}
+	return pty, nil
+}
+
+// rendererByName finds one of the renderers that shows every pixel
+func rendererByName(name string) (terminal.Renderer, bool) {
+	for _, r := range terminal.Lossless {
+		if r.Name == name {
+			return r, true
+		}
+	}
+	return terminal.Renderer{}, false
+}
@@ -0,0 +1,444 @@
+// Package ssd1306emu is a software SSD1306 for running the OLED code
+// without hardware.
+//
+// A Bus stands in for machine.I2C0: hand it to ssd1306.NewI2C and the real
+// driver talks to an emulated controller that decodes the command and data
+// stream the way the chip does (addressing modes, page/column pointers,
+// contrast, invert, remapping) and keeps the panel's GDDRAM, which can be
+// inspected pixel by pixel or saved as a PNG.
+//
+//	bus := ssd1306emu.NewBus()
+//	oled := bus.Attach(0x3C, 128, 64)
+//	display := ssd1306.NewI2C(bus)
+//	display.Configure(ssd1306.Config{Address: 0x3C, Width: 128, Height: 64})
+//	grid.DrawToOLED(display)
+//	oled.Pixel(64, 32) // what the panel shows
+//
+// AttachMux adds a TCA9548A multiplexer, with devices on its channels, for
+// running several panels at the same address.
+package ssd1306emu
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+)
+
+// I2C control bytes: Co (continuation) and D/C# (data or command)
+const (
+	controlCo   = 0x80
+	controlData = 0x40
+)
+
+// Memory addressing modes (command 0x20)
+const (
+	HorizontalMode = 0
+	VerticalMode   = 1
+	PageMode       = 2
+)
+
+// Bus is an in-memory I2C bus; it satisfies drivers.I2C
+type Bus struct {
+	devices map[uint16]*Device
+	muxes   map[uint16]*Mux
+}
+
+// NewBus creates an empty bus
+func NewBus() *Bus {
+	return &Bus{devices: map[uint16]*Device{}, muxes: map[uint16]*Mux{}}
+}
+
+// Attach puts a width x height SSD1306 on the bus at addr
+func (b *Bus) Attach(addr uint16, width, height int) *Device {
+	d := New(width, height)
+	b.devices[addr] = d
+	return d
+}
+
+// AttachMux puts a TCA9548A multiplexer on the bus at addr (usually 0x70)
+func (b *Bus) AttachMux(addr uint16) *Mux {
+	m := &Mux{}
+	for i := range m.channels {
+		m.channels[i] = NewBus()
+	}
+	b.muxes[addr] = m
+	return m
+}
+
+// Tx performs an I2C transaction; writes to an address with no device
+// fail the way a NACK would. Devices behind a multiplexer answer while
+// their channel is switched in.
+func (b *Bus) Tx(addr uint16, w, r []byte) error {
+	if m, ok := b.muxes[addr]; ok {
+		return m.tx(w, r)
+	}
+	if d, ok := b.devices[addr]; ok {
+		return d.Tx(addr, w, r)
+	}
+	acked := false
+	for _, m := range b.muxes {
+		for n, ch := range m.channels {
+			if m.control&(1<<n) == 0 {
+				continue
+			}
+			if err := ch.Tx(addr, w, r); err == nil {
+				acked = true
+			}
+		}
+	}
+	if !acked {
+		return fmt.Errorf("ssd1306emu: no device at address 0x%02X", addr)
+	}
+	return nil
+}
+
+// Mux is an emulated TCA9548A: eight downstream buses, each connected to
+// the bus the Mux is on while its bit of the control register is set
+type Mux struct {
+	channels [8]*Bus
+	control  byte
+}
+
+// Channel returns downstream bus n (0-7), to Attach devices to
+func (m *Mux) Channel(n int) *Bus {
+	return m.channels[n&7]
+}
+
+// Control returns the control register: bit n set means channel n is
+// switched in
+func (m *Mux) Control() byte {
+	return m.control
+}
+
+// tx writes the control register, or reads it back
+func (m *Mux) tx(w, r []byte) error {
+	if len(w) > 0 {
+		m.control = w[len(w)-1]
+	}
+	for i := range r {
+		r[i] = m.control
+	}
+	return nil
+}
+
+// Stats counts the traffic a Device has received
+type Stats struct {
+	Transactions int // I2C transactions
+	Bytes        int // bytes on the wire, control bytes included
+	Commands     int // command bytes (parameters included)
+	DataBytes    int // bytes written to GDDRAM
+}
+
+// Device is one emulated SSD1306 controller and its panel
+type Device struct {
+	width, height int
+	ram           []byte // GDDRAM: one byte per column per 8-row page
+
+	// Address pointers
+	mode                 int
+	col, page            int
+	colStart, colEnd     int
+	pageStart, pageEnd   int
+	pageModeColumnOffset int // column start for page addressing mode
+
+	// Display state
+	on          bool
+	allOn       bool // 0xA5: every pixel on regardless of RAM
+	inverted    bool
+	contrast    uint8
+	segRemap    bool // 0xA1
+	comScanDec  bool // 0xC8
+	startLine   int
+	offset      int
+	chargePump  bool
+	scrolling   bool
+	multiplex   int
+	pending     []byte // command waiting for its parameters
+	pendingWant int
+
+	stats Stats
+}
+
+// New creates a controller in its power-on reset state
+func New(width, height int) *Device {
+	d := &Device{
+		width:     width,
+		height:    height,
+		ram:       make([]byte, width*((height+7)/8)),
+		contrast:  0x7F,
+		mode:      PageMode,
+		multiplex: height,
+	}
+	d.colEnd = width - 1
+	d.pageEnd = (height+7)/8 - 1
+	return d
+}
+
+// Tx receives an I2C write. The first byte of every message is a control
+// byte; with Co clear the rest is a stream of commands or data, with Co set
+// control bytes and single command/data bytes alternate.
+func (d *Device) Tx(addr uint16, w, r []byte) error {
+	d.stats.Transactions++
+	d.stats.Bytes += len(w)
+	for i := range r {
+		r[i] = 0 // reads aren't supported over I2C on the SSD1306
+	}
+
+	for len(w) > 0 {
+		control := w[0]
+		w = w[1:]
+		if control&controlCo == 0 {
+			// Stream: everything that follows has the same meaning
+			for _, b := range w {
+				d.receive(b, control&controlData != 0)
+			}
+			return nil
+		}
+		if len(w) == 0 {
+			return nil
+		}
+		d.receive(w[0], control&controlData != 0)
+		w = w[1:]
+	}
+	return nil
+}
+
+func (d *Device) receive(b byte, data bool) {
+	if data {
+		d.writeData(b)
+	} else {
+		d.command(b)
+	}
+}
+
+// writeData stores a byte at the address pointer and advances it
+func (d *Device) writeData(b byte) {
+	d.stats.DataBytes++
+	if d.col < d.width && d.page < len(d.ram)/d.width {
+		d.ram[d.page*d.width+d.col] = b
+	}
+
+	switch d.mode {
+	case HorizontalMode:
+		d.col++
+		if d.col > d.colEnd {
+			d.col = d.colStart
+			d.page++
+			if d.page > d.pageEnd {
+				d.page = d.pageStart
+			}
+		}
+	case VerticalMode:
+		d.page++
+		if d.page > d.pageEnd {
+			d.page = d.pageStart
+			d.col++
+			if d.col > d.colEnd {
+				d.col = d.colStart
+			}
+		}
+	default: // page mode: the column wraps, the page stays put
+		d.col++
+		if d.col >= d.width {
+			d.col = d.pageModeColumnOffset
+		}
+	}
+}
+
+// paramCount is how many parameter bytes follow each multi-byte command
+var paramCount = map[byte]int{
+	0x20: 1, // memory addressing mode
+	0x21: 2, // column address
+	0x22: 2, // page address
+	0x26: 6, // right horizontal scroll
+	0x27: 6, // left horizontal scroll
+	0x29: 5, // vertical and right scroll
+	0x2A: 5, // vertical and left scroll
+	0x81: 1, // contrast
+	0x8D: 1, // charge pump
+	0xA3: 2, // vertical scroll area
+	0xA8: 1, // multiplex ratio
+	0xD3: 1, // display offset
+	0xD5: 1, // clock divide
+	0xD9: 1, // pre-charge period
+	0xDA: 1, // COM pins
+	0xDB: 1, // VCOMH deselect level
+}
+
+// command handles a command byte, collecting parameters as they arrive
+// (the driver sends every byte in its own transaction)
+func (d *Device) command(b byte) {
+	d.stats.Commands++
+	if d.pending != nil {
+		d.pending = append(d.pending, b)
+		if len(d.pending) == d.pendingWant+1 {
+			d.execute(d.pending[0], d.pending[1:])
+			d.pending = nil
+		}
+		return
+	}
+	if n, ok := paramCount[b]; ok {
+		d.pending = []byte{b}
+		d.pendingWant = n
+		return
+	}
+	d.execute(b, nil)
+}
+
+func (d *Device) execute(cmd byte, params []byte) {
+	pages := (d.height + 7) / 8
+	switch {
+	case cmd == 0x20:
+		d.mode = int(params[0] & 0x03)
+	case cmd == 0x21:
+		d.colStart = min(int(params[0]&0x7F), d.width-1)
+		d.colEnd = min(int(params[1]&0x7F), d.width-1)
+		d.col = d.colStart
+	case cmd == 0x22:
+		d.pageStart = min(int(params[0]&0x07), pages-1)
+		d.pageEnd = min(int(params[1]&0x07), pages-1)
+		d.page = d.pageStart
+	case cmd == 0x81:
+		d.contrast = params[0]
+	case cmd == 0x8D:
+		d.chargePump = params[0]&0x04 != 0
+	case cmd == 0xA8:
+		d.multiplex = int(params[0]&0x3F) + 1
+	case cmd == 0xD3:
+		d.offset = int(params[0] & 0x3F)
+	case cmd == 0x2E:
+		d.scrolling = false
+	case cmd == 0x2F:
+		d.scrolling = true
+	case cmd <= 0x0F: // lower column start (page mode)
+		d.pageModeColumnOffset = d.pageModeColumnOffset&0xF0 | int(cmd&0x0F)
+		d.col = d.pageModeColumnOffset
+	case cmd <= 0x1F: // higher column start (page mode), 4 bits for the SH1106's 132 columns
+		d.pageModeColumnOffset = d.pageModeColumnOffset&0x0F | int(cmd&0x0F)<<4
+		d.col = d.pageModeColumnOffset
+	case cmd >= 0x40 && cmd <= 0x7F:
+		d.startLine = int(cmd & 0x3F)
+	case cmd == 0xA0 || cmd == 0xA1:
+		d.segRemap = cmd == 0xA1
+	case cmd == 0xA4 || cmd == 0xA5:
+		d.allOn = cmd == 0xA5
+	case cmd == 0xA6 || cmd == 0xA7:
+		d.inverted = cmd == 0xA7
+	case cmd == 0xAE || cmd == 0xAF:
+		d.on = cmd == 0xAF
+	case cmd >= 0xB0 && cmd <= 0xB7: // page start (page mode)
+		d.page = min(int(cmd&0x07), pages-1)
+	case cmd == 0xC0 || cmd == 0xC8:
+		d.comScanDec = cmd == 0xC8
+	}
+	// Anything else (0xE3 NOP, timing settings) has no visible effect
+}
+
+// RAM returns the controller's GDDRAM in SSD1306 page layout, the same
+// layout as display.Framebuffer.Buffer and the driver's buffer
+func (d *Device) RAM() []byte {
+	return d.ram
+}
+
+// RAMPixel reports whether a bit is set in GDDRAM at column x, row y,
+// ignoring how the panel is currently showing it
+func (d *Device) RAMPixel(x, y int) bool {
+	if x < 0 || x >= d.width || y < 0 || y >= d.height {
+		return false
+	}
+	return d.ram[(y/8)*d.width+x]>>(y%8)&1 == 1
+}
+
+// Pixel reports whether the panel pixel at (x, y) is lit, taking into
+// account display on/off, entire-display-on, inversion, start line,
+// offset and remapping.
+//
+// Modules are wired so that the driver's default (segment remap on, COM
+// scan descending) shows the image upright; the other settings mirror it.
+func (d *Device) Pixel(x, y int) bool {
+	if x < 0 || x >= d.width || y < 0 || y >= d.height || !d.on {
+		return false
+	}
+	if y >= d.multiplex {
+		return false // rows past the multiplex ratio aren't driven
+	}
+	if d.allOn {
+		return true
+	}
+
+	col, row := x, y
+	if !d.segRemap {
+		col = d.width - 1 - col
+	}
+	if !d.comScanDec {
+		row = d.multiplex - 1 - row
+	}
+	row = (row + d.startLine + d.offset) % d.height
+
+	return d.RAMPixel(col, row) != d.inverted
+}
+
+// Image returns what the panel shows, with lit pixels as bright as the
+// contrast setting makes them
+func (d *Device) Image() *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, d.width, d.height))
+	// Even at contrast 0 a lit pixel is still visible
+	lit := color.Gray{Y: uint8(64 + int(d.contrast)*191/255)}
+	for y := 0; y < d.height; y++ {
+		for x := 0; x < d.width; x++ {
+			if d.Pixel(x, y) {
+				img.SetGray(x, y, lit)
+			}
+		}
+	}
+	return img
+}
+
+// WritePNG saves what the panel shows, scaled up so it's easy to look at
+func (d *Device) WritePNG(w io.Writer, scale int) error {
+	src := d.Image()
+	if scale <= 1 {
+		return png.Encode(w, src)
+	}
+	dst := image.NewGray(image.Rect(0, 0, d.width*scale, d.height*scale))
+	for y := 0; y < d.height*scale; y++ {
+		for x := 0; x < d.width*scale; x++ {
+			dst.SetGray(x, y, src.GrayAt(x/scale, y/scale))
+		}
+	}
+	return png.Encode(w, dst)
+}
+
+// Size returns the panel size in pixels
+func (d *Device) Size() (width, height int) {
+	return d.width, d.height
+}
+
+// On reports whether the display is switched on (0xAF)
+func (d *Device) On() bool { return d.on }
+
+// Inverted reports whether inverse display is on (0xA7)
+func (d *Device) Inverted() bool { return d.inverted }
+
+// Contrast returns the contrast setting (0x81)
+func (d *Device) Contrast() uint8 { return d.contrast }
+
+// AddressingMode returns HorizontalMode, VerticalMode or PageMode
+func (d *Device) AddressingMode() int { return d.mode }
+
+// Pointer returns the current column and page address pointers
+func (d *Device) Pointer() (col, page int) { return d.col, d.page }
+
+// ChargePump reports whether the charge pump is enabled (0x8D 0x14)
+func (d *Device) ChargePump() bool { return d.chargePump }
+
+// Scrolling reports whether hardware scrolling is active
+func (d *Device) Scrolling() bool { return d.scrolling }
+
+// Stats returns the traffic received so far
+func (d *Device) Stats() Stats { return d.stats }
+
+// ResetStats zeroes the traffic counters
+func (d *Device) ResetStats() { d.stats = Stats{} }
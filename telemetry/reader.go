@@ -0,0 +1,216 @@
+package telemetry
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Record is a record read back from the serial port
+type Record struct {
+	Time   int64 // milliseconds since boot
+	Kind   string
+	Fields []Field
+}
+
+// Get returns the field called name
+func (r *Record) Get(name string) (Field, bool) {
+	for _, f := range r.Fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
+
+// Value returns the field's value as text
+func (f Field) Value() string {
+	if f.str {
+		return f.Str
+	}
+	return strconv.FormatInt(f.Int, 10)
+}
+
+// MarshalJSON writes the record as the JSON format sends it, whichever
+// format it came in
+func (r *Record) MarshalJSON() ([]byte, error) {
+	return bytes.TrimSuffix(appendJSON(nil, r.Time, r.Kind, r.Fields), []byte("\n")), nil
+}
+
+// Reader splits what comes in on the serial port into telemetry records,
+// in either format, and the other lines: the log and console output. It
+// is for desktop tools; the firmware only sends.
+type Reader struct {
+	br *bufio.Reader
+}
+
+// NewReader reads from r
+func NewReader(r io.Reader) *Reader {
+	return &Reader{br: bufio.NewReader(r)}
+}
+
+// ErrMalformed is returned for a CBOR record that can't be read
+var ErrMalformed = errors.New("telemetry: malformed record")
+
+// Next returns the next record, or if the next thing isn't one, the next
+// line without its line ending. Malformed JSON records come back as
+// lines; a malformed CBOR record is an error, after which reading can go
+// on from the next line.
+func (r *Reader) Next() (*Record, string, error) {
+	if r.isCBOR() {
+		r.br.Discard(len(cborSelfDescribe))
+		rec, err := readCBOR(r.br)
+		if err != nil {
+			return nil, "", err
+		}
+		if b, err := r.br.ReadByte(); err == nil && b != '\n' {
+			r.br.UnreadByte()
+		}
+		return rec, "", nil
+	}
+
+	line, err := r.br.ReadString('\n')
+	if line == "" && err != nil {
+		return nil, "", err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if strings.HasPrefix(line, "{") {
+		if rec, err := parseJSON(line); err == nil {
+			return rec, "", nil
+		}
+	}
+	return nil, line, nil
+}
+
+// isCBOR reports whether a CBOR record comes next. It only waits for
+// more than a byte if that byte starts the tag.
+func (r *Reader) isCBOR() bool {
+	if b, err := r.br.Peek(1); err != nil || b[0] != cborSelfDescribe[0] {
+		return false
+	}
+	head, err := r.br.Peek(len(cborSelfDescribe))
+	return err == nil && bytes.Equal(head, cborSelfDescribe)
+}
+
+// parseJSON reads a JSON record, keeping the fields in order
+func parseJSON(line string) (*Record, error) {
+	dec := json.NewDecoder(strings.NewReader(line))
+	dec.UseNumber()
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return nil, ErrMalformed
+	}
+	rec := &Record{}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		value, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		name, _ := key.(string)
+		switch v := value.(type) {
+		case json.Number:
+			n, err := v.Int64()
+			if err != nil {
+				return nil, ErrMalformed
+			}
+			rec.set(name, Field{Name: name, Int: n})
+		case string:
+			rec.set(name, Field{Name: name, Str: v, str: true})
+		default:
+			return nil, ErrMalformed
+		}
+	}
+	return rec, nil
+}
+
+// set adds a field, or fills in the time or kind
+func (r *Record) set(name string, f Field) {
+	switch {
+	case name == "t" && !f.str:
+		r.Time = f.Int
+	case name == "k" && f.str:
+		r.Kind = f.Str
+	default:
+		r.Fields = append(r.Fields, f)
+	}
+}
+
+// readCBOR reads the map of a CBOR record, after its tag
+func readCBOR(br *bufio.Reader) (*Record, error) {
+	major, n, err := cborReadHead(br)
+	if err != nil || major != cborMap {
+		return nil, ErrMalformed
+	}
+	rec := &Record{}
+	for ; n > 0; n-- {
+		major, size, err := cborReadHead(br)
+		if err != nil || major != cborText {
+			return nil, ErrMalformed
+		}
+		name, err := cborReadText(br, size)
+		if err != nil {
+			return nil, err
+		}
+		major, v, err := cborReadHead(br)
+		if err != nil {
+			return nil, err
+		}
+		switch major {
+		case cborUint:
+			rec.set(name, Field{Name: name, Int: int64(v)})
+		case cborNegInt:
+			rec.set(name, Field{Name: name, Int: -1 - int64(v)})
+		case cborText:
+			s, err := cborReadText(br, v)
+			if err != nil {
+				return nil, err
+			}
+			rec.set(name, Field{Name: name, Str: s, str: true})
+		default:
+			return nil, ErrMalformed
+		}
+	}
+	return rec, nil
+}
+
+// cborReadHead reads a data item head: major type and argument
+func cborReadHead(br *bufio.Reader) (byte, uint64, error) {
+	b, err := br.ReadByte()
+	if err != nil {
+		return 0, 0, err
+	}
+	major, info := b&0xE0, b&0x1F
+	var size int
+	switch {
+	case info < 24:
+		return major, uint64(info), nil
+	case info <= 27:
+		size = 1 << (info - 24)
+	default:
+		return 0, 0, ErrMalformed
+	}
+	var buf [8]byte
+	if _, err := io.ReadFull(br, buf[8-size:]); err != nil {
+		return 0, 0, err
+	}
+	return major, binary.BigEndian.Uint64(buf[:]), nil
+}
+
+func cborReadText(br *bufio.Reader, n uint64) (string, error) {
+	if n > 1024 {
+		return "", ErrMalformed
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(br, b); err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,220 @@
+// Package telemetry sends the games' numbers (generation, population,
+// score, frame rate) over the serial port as records a desktop tool can
+// record and plot.
+//
+// Records share the port with the log, one per line. In JSON they look
+// like
+//
+//	{"t":12345,"k":"life","gen":120,"pop":567,"fps":20}
+//
+// where t is milliseconds since boot and k the kind of record. In CBOR
+// each record is the same map, tagged as CBOR (0xD9 0xD9 0xF7) so a
+// reader can tell it from log text, and followed by a newline. A Reader
+// reads both formats back, on the desktop.
+package telemetry
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Format is how records are encoded, if at all
+type Format int
+
+const (
+	Off Format = iota
+	JSON
+	CBOR
+)
+
+var formatNames = []string{"off", "json", "cbor"}
+
+// String names the format
+func (f Format) String() string {
+	if f < 0 || int(f) >= len(formatNames) {
+		return "?"
+	}
+	return formatNames[f]
+}
+
+// ParseFormat finds a format by name, in any case
+func ParseFormat(name string) (Format, error) {
+	for i, n := range formatNames {
+		if strings.EqualFold(n, name) {
+			return Format(i), nil
+		}
+	}
+	return Off, errors.New("telemetry: unknown format " + strconv.Quote(name))
+}
+
+// Interval is how often the games send their records
+const Interval = time.Second
+
+// Field is one named value in a record: an integer or a string
+type Field struct {
+	Name string
+	Int  int64
+	Str  string
+	str  bool
+}
+
+// Int makes an integer field
+func Int(name string, v int) Field {
+	return Field{Name: name, Int: int64(v)}
+}
+
+// String makes a string field
+func String(name, v string) Field {
+	return Field{Name: name, Str: v, str: true}
+}
+
+var (
+	mu     sync.Mutex
+	output io.Writer = os.Stderr // with the log, on the serial port
+	format Format
+	start  = time.Now()
+	buf    []byte
+)
+
+// SetOutput sends the records to w
+func SetOutput(w io.Writer) {
+	mu.Lock()
+	output = w
+	mu.Unlock()
+}
+
+// SetFormat chooses the encoding; Off (the default) sends nothing
+func SetFormat(f Format) {
+	mu.Lock()
+	format = f
+	mu.Unlock()
+}
+
+// Enabled reports whether records are being sent, to skip gathering
+// them when not
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return format != Off
+}
+
+// Send sends a record of kind (such as "life") with fields
+func Send(kind string, fields ...Field) {
+	mu.Lock()
+	defer mu.Unlock()
+	t := time.Since(start).Milliseconds()
+	switch format {
+	case JSON:
+		buf = appendJSON(buf[:0], t, kind, fields)
+	case CBOR:
+		buf = appendCBOR(buf[:0], t, kind, fields)
+	default:
+		return
+	}
+	output.Write(buf)
+}
+
+func appendJSON(b []byte, t int64, kind string, fields []Field) []byte {
+	b = append(b, `{"t":`...)
+	b = strconv.AppendInt(b, t, 10)
+	b = append(b, `,"k":`...)
+	b = appendJSONString(b, kind)
+	for _, f := range fields {
+		b = append(b, ',')
+		b = appendJSONString(b, f.Name)
+		b = append(b, ':')
+		if f.str {
+			b = appendJSONString(b, f.Str)
+		} else {
+			b = strconv.AppendInt(b, f.Int, 10)
+		}
+	}
+	return append(b, "}\n"...)
+}
+
+// appendJSONString appends s as a JSON string. Names come from RLE files,
+// so control characters are escaped (Go's quoting isn't JSON) and bad
+// UTF-8 is replaced, keeping the record on one line a JSON reader takes.
+func appendJSONString(b []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+	b = append(b, '"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b = append(b, '\\', byte(r))
+		case r == '\n':
+			b = append(b, `\n`...)
+		case r == '\r':
+			b = append(b, `\r`...)
+		case r == '\t':
+			b = append(b, `\t`...)
+		case r < 0x20:
+			b = append(b, '\\', 'u', '0', '0', hex[r>>4], hex[r&0xF])
+		default:
+			b = utf8.AppendRune(b, r) // U+FFFD for bad UTF-8
+		}
+	}
+	return append(b, '"')
+}
+
+// cborSelfDescribe is tag 55799, which marks the start of CBOR data
+var cborSelfDescribe = []byte{0xD9, 0xD9, 0xF7}
+
+// CBOR major types
+const (
+	cborUint   = 0 << 5
+	cborNegInt = 1 << 5
+	cborText   = 3 << 5
+	cborMap    = 5 << 5
+)
+
+func appendCBOR(b []byte, t int64, kind string, fields []Field) []byte {
+	b = append(b, cborSelfDescribe...)
+	b = cborHead(b, cborMap, uint64(2+len(fields)))
+	b = cborString(b, "t")
+	b = cborInt(b, t)
+	b = cborString(b, "k")
+	b = cborString(b, kind)
+	for _, f := range fields {
+		b = cborString(b, f.Name)
+		if f.str {
+			b = cborString(b, f.Str)
+		} else {
+			b = cborInt(b, f.Int)
+		}
+	}
+	return append(b, '\n')
+}
+
+// cborHead appends a data item head: major type and argument
+func cborHead(b []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(b, major|byte(n))
+	case n <= 0xFF:
+		return append(b, major|24, byte(n))
+	case n <= 0xFFFF:
+		return binary.BigEndian.AppendUint16(append(b, major|25), uint16(n))
+	case n <= 0xFFFFFFFF:
+		return binary.BigEndian.AppendUint32(append(b, major|26), uint32(n))
+	}
+	return binary.BigEndian.AppendUint64(append(b, major|27), n)
+}
+
+func cborInt(b []byte, v int64) []byte {
+	if v < 0 {
+		return cborHead(b, cborNegInt, uint64(-1-v))
+	}
+	return cborHead(b, cborUint, uint64(v))
+}
+
+func cborString(b []byte, s string) []byte {
+	return append(cborHead(b, cborText, uint64(len(s))), s...)
+}
@@ -0,0 +1,45 @@
+package telemetry
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestJSONStrings(t *testing.T) {
+	names := []string{
+		"GLIDER",
+		"bell\a tab\t vt\v nul\x00 esc\x1b",
+		`quote" backslash\ slash/`,
+		"line\r\nbreak",
+		"Gosper's gun ✓",
+		"bad \xff UTF-8",
+	}
+	for _, name := range names {
+		line := appendJSON(nil, 1234, "life", []Field{String("pattern", name), Int("gen", 5)})
+		if bytes.Count(line, []byte("\n")) != 1 || line[len(line)-1] != '\n' {
+			t.Errorf("%q: record isn't one line: %q", name, line)
+		}
+
+		var rec map[string]any
+		if err := json.Unmarshal(line, &rec); err != nil {
+			t.Errorf("%q: %s isn't JSON: %v", name, line, err)
+			continue
+		}
+		want := strings.ToValidUTF8(name, "�")
+		if rec["pattern"] != want {
+			t.Errorf("%q: read back as %q", name, rec["pattern"])
+		}
+
+		// And the package's own reader takes it as a record
+		got, text, err := NewReader(bytes.NewReader(line)).Next()
+		if err != nil || got == nil {
+			t.Errorf("%q: Reader gave %q, %v", name, text, err)
+			continue
+		}
+		if f, _ := got.Get("pattern"); f.Str != want {
+			t.Errorf("%q: Reader read %q", name, f.Str)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package terminal
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// xterm mouse reporting modes:
+// 1000 = report clicks, 1002 = also report motion while a button is held,
+// 1006 = SGR extended coordinates (no 223 column limit, distinct release)
+const (
+	enableMouse  = "\033[?1000h\033[?1002h\033[?1006h"
+	disableMouse = "\033[?1006l\033[?1002l\033[?1000l"
+)
+
+// EnableMouse turns on xterm SGR mouse reporting
+func EnableMouse(w io.Writer) {
+	fmt.Fprint(w, enableMouse)
+}
+
+// DisableMouse turns mouse reporting back off.
+// Always call this before exiting, otherwise the shell receives the reports.
+func DisableMouse(w io.Writer) {
+	fmt.Fprint(w, disableMouse)
+}
+
+// EventKind says what kind of input an Event carries
+type EventKind int
+
+const (
+	KeyPress     EventKind = iota // a key was pressed, see Event.Key
+	MousePress                    // a mouse button went down
+	MouseDrag                     // the mouse moved with a button held
+	MouseRelease                  // a mouse button came up
+)
+
+// Mouse buttons as reported by xterm
+const (
+	ButtonLeft   = 0
+	ButtonMiddle = 1
+	ButtonRight  = 2
+)
+
+// Keys that don't have a character of their own are reported with these
+// runes from the Unicode private use area
+const (
+	KeyUp rune = 0xE000 + iota
+	KeyDown
+	KeyRight
+	KeyLeft
+)
+
+// Event is a single key press or mouse report
+type Event struct {
+	Kind   EventKind
+	Key    rune // for KeyPress
+	Button int  // for mouse events
+	Col    int  // 0-based screen column of the mouse
+	Row    int  // 0-based screen row of the mouse
+}
+
+// ReadEvents decodes key presses and SGR mouse reports from r and sends
+// them to events until r returns an error.
+func ReadEvents(r io.Reader, events chan<- Event) error {
+	br := bufio.NewReader(r)
+	for {
+		c, _, err := br.ReadRune()
+		if err != nil {
+			return err
+		}
+		if c != '\033' {
+			events <- Event{Kind: KeyPress, Key: c}
+			continue
+		}
+
+		// Escape sequence: only arrow keys and mouse reports are understood.
+		// Anything else after ESC (Alt+key, or ESC pressed on its own before
+		// a key) is read again as a key of its own.
+		if c, err = readByte(br); err != nil {
+			return err
+		}
+		if c != '[' {
+			br.UnreadByte()
+			continue
+		}
+		if c, err = readByte(br); err != nil {
+			return err
+		}
+		switch c {
+		case 'A', 'B', 'C', 'D':
+			// Arrow keys: ESC [ A..D
+			events <- Event{Kind: KeyPress, Key: KeyUp + c - 'A'}
+			continue
+		case '<':
+			// SGR mouse report, handled below
+		default:
+			// Other keys (Delete is ESC [ 3 ~, Ctrl+Up ESC [ 1 ; 5 A) are
+			// dropped whole, so their parameters aren't read as keys
+			if err := skipCSI(br, c); err != nil {
+				return err
+			}
+			continue
+		}
+
+		ev, err := readMouse(br)
+		if err != nil {
+			return err
+		}
+		if ev != nil {
+			events <- *ev
+		}
+	}
+}
+
+// readMouse decodes the rest of an SGR mouse report: "<b;x;yM" or "<b;x;ym".
+// It returns nil for reports we don't use (such as the scroll wheel).
+func readMouse(br *bufio.Reader) (*Event, error) {
+	var fields [3]int
+	field := 0
+	var num []byte
+	for {
+		c, err := readByte(br)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case c >= '0' && c <= '9':
+			num = append(num, byte(c))
+			continue
+		case c == ';' || c == 'M' || c == 'm':
+			if field < len(fields) {
+				fields[field], _ = strconv.Atoi(string(num))
+			}
+			field++
+			num = num[:0]
+		default:
+			return nil, nil // malformed, drop it
+		}
+		if c == ';' {
+			continue
+		}
+
+		code, col, row := fields[0], fields[1]-1, fields[2]-1
+		if code&64 != 0 {
+			return nil, nil // scroll wheel
+		}
+		ev := &Event{Button: code & 3, Col: col, Row: row}
+		switch {
+		case c == 'm':
+			ev.Kind = MouseRelease
+		case code&32 != 0:
+			ev.Kind = MouseDrag
+		default:
+			ev.Kind = MousePress
+		}
+		return ev, nil
+	}
+}
+
+// skipCSI reads the rest of a control sequence that started with c,
+// up to its final byte (@ to ~). Parameter and intermediate bytes come
+// before it; anything else ends the sequence early and is read again.
+func skipCSI(br *bufio.Reader, c rune) error {
+	for {
+		switch {
+		case c >= 0x40 && c <= 0x7E:
+			return nil
+		case c < 0x20 || c > 0x3F:
+			return br.UnreadByte()
+		}
+		var err error
+		if c, err = readByte(br); err != nil {
+			return err
+		}
+	}
+}
+
+func readByte(br *bufio.Reader) (rune, error) {
+	b, err := br.ReadByte()
+	return rune(b), err
+}
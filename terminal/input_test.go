@@ -0,0 +1,66 @@
+package terminal
+
+import (
+	"strings"
+	"testing"
+)
+
+func readAll(t *testing.T, input string) []Event {
+	t.Helper()
+	events := make(chan Event, 64)
+	ReadEvents(strings.NewReader(input), events)
+	close(events)
+	var got []Event
+	for ev := range events {
+		got = append(got, ev)
+	}
+	return got
+}
+
+func TestReadEventsKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		keys  string
+	}{
+		{"plain", "ab", "ab"},
+		{"arrow", "\033[Ax", string(KeyUp) + "x"},
+		{"alt+key", "\033q", "q"},
+		{"lone escape before a key", "\033a\033\033[Bb", "a" + string(KeyDown) + "b"},
+		{"unknown sequence", "\033[Zc", "c"},
+		{"delete", "\033[3~d", "d"},
+		{"ctrl+up", "\033[1;5Ae", "e"},
+		{"cut short", "\033[12\033[Bf", string(KeyDown) + "f"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var keys []rune
+			for _, ev := range readAll(t, tt.input) {
+				if ev.Kind != KeyPress {
+					t.Fatalf("got %+v, want only key presses", ev)
+				}
+				keys = append(keys, ev.Key)
+			}
+			if string(keys) != tt.keys {
+				t.Errorf("keys %q, want %q", string(keys), tt.keys)
+			}
+		})
+	}
+}
+
+func TestReadEventsMouse(t *testing.T) {
+	got := readAll(t, "\033[<0;5;3M\033[<32;6;3M\033[<0;6;3m\033[<64;1;1M")
+	want := []Event{
+		{Kind: MousePress, Button: ButtonLeft, Col: 4, Row: 2},
+		{Kind: MouseDrag, Button: ButtonLeft, Col: 5, Row: 2},
+		{Kind: MouseRelease, Button: ButtonLeft, Col: 5, Row: 2},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("event %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package terminal
+
+// Layout describes where a board was drawn on the screen, so a mouse
+// position can be turned back into a cell.
+type Layout struct {
+	Top, Left      int // screen row/column (0-based) of the first board character
+	ScaleX, ScaleY int // cells covered by one character in each direction
+	Width, Height  int // board size in cells
+}
+
+// Cell returns the board cell under the screen position (col, row).
+// When one character covers several cells (a sampled or packed renderer),
+// this is the top-left cell of that block: the mouse only reports which
+// character it is over, so the others can't be told apart, and can't be
+// reached. To edit every cell, draw them a character or more each (Full,
+// or a Viewport zoomed in to at least ScaleX and ScaleY).
+// ok is false when the position is outside the board.
+func (l Layout) Cell(col, row int) (x, y int, ok bool) {
+	if col < l.Left || row < l.Top {
+		return 0, 0, false
+	}
+	x = (col - l.Left) * max(l.ScaleX, 1)
+	y = (row - l.Top) * max(l.ScaleY, 1)
+	if x >= l.Width || y >= l.Height {
+		return 0, 0, false
+	}
+	return x, y, true
+}
@@ -0,0 +1,38 @@
+package terminal
+
+import "testing"
+
+// Every cell must be reachable by clicking once the view is zoomed in to
+// the renderer's block size, as the terminal version does while editing
+func TestLayoutReachesEveryCellWhenZoomed(t *testing.T) {
+	for _, r := range []Renderer{Full, HalfBlock, Braille, Compact} {
+		board := &testBoard{w: 16, h: 8}
+		view := NewViewport(board)
+		for view.Zoom() < max(r.ScaleX, r.ScaleY) {
+			view.ZoomIn()
+		}
+		w, h := view.ZoomedSize()
+		cols, rows := r.TextSize(w, h)
+		layout := r.Layout(view, 2, 1)
+
+		reached := map[[2]int]bool{}
+		for row := 0; row < rows; row++ {
+			for col := 0; col < cols; col++ {
+				vx, vy, ok := layout.Cell(col+1, row+2)
+				if !ok {
+					t.Fatalf("%s: (%d, %d) outside the board", r.Name, col, row)
+				}
+				x, y := view.ToBoard(vx, vy)
+				reached[[2]int{x, y}] = true
+			}
+		}
+		if len(reached) != board.w*board.h {
+			t.Errorf("%s: %d of %d cells reachable", r.Name, len(reached), board.w*board.h)
+		}
+	}
+}
+
+type testBoard struct{ w, h int }
+
+func (b *testBoard) Size() (int, int)    { return b.w, b.h }
+func (b *testBoard) Alive(x, y int) bool { return false }
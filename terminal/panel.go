@@ -0,0 +1,118 @@
+package terminal
+
+import (
+	"io"
+	"strings"
+
+	"gameoflife/display"
+)
+
+// Panel is a display.Display that shows the OLED framebuffer in the
+// terminal, inside a border. It uses the half-block renderer by default so
+// every pixel is visible: a 128x64 display takes 130x34 characters.
+//
+// Like the SSD1306 it takes the display off/on and contrast commands: off
+// shows an empty panel, and a low contrast draws it faint.
+type Panel struct {
+	*display.Framebuffer
+	Renderer Renderer
+	Caption  string // shown under the border, e.g. key bindings
+	screen   *Screen
+
+	off         bool
+	contrast    uint8
+	setContrast bool // the next command byte is the contrast
+}
+
+// SSD1306 commands the panel follows
+const (
+	cmdContrast   = 0x81
+	cmdDisplayOff = 0xAE
+	cmdDisplayOn  = 0xAF
+)
+
+// dimContrast is the contrast below which the panel is drawn faint
+const dimContrast = 64
+
+// NewPanel creates a panel of width x height pixels drawing to w
+func NewPanel(w io.Writer, width, height int16) *Panel {
+	return &Panel{
+		Framebuffer: display.NewFramebuffer(width, height),
+		Renderer:    HalfBlock,
+		screen:      NewScreen(w),
+		contrast:    0x7F,
+	}
+}
+
+// Command follows the SSD1306 power and contrast commands, redrawing the
+// panel when they change it, and ignores the rest
+func (p *Panel) Command(cmd uint8) {
+	switch {
+	case p.setContrast:
+		p.setContrast = false
+		p.contrast = cmd
+	case cmd == cmdContrast:
+		p.setContrast = true
+		return
+	case cmd == cmdDisplayOff:
+		p.off = true
+	case cmd == cmdDisplayOn:
+		p.off = false
+	default:
+		return
+	}
+	p.screen.Draw(p.Frame())
+}
+
+// Display draws the framebuffer on the terminal
+func (p *Panel) Display() error {
+	p.Framebuffer.Display()
+	return p.screen.Draw(p.Frame())
+}
+
+// Frame returns the bordered panel as screen lines
+func (p *Panel) Frame() [][]Cell {
+	board := pixels{p.Framebuffer, p.off}
+	inner := p.Renderer.Render(board)
+	cols, _ := p.Renderer.TextSize(board.Size())
+
+	style := ""
+	if p.contrast < dimContrast {
+		style = "2" // faint
+	}
+	lines := make([][]Cell, 0, len(inner)+2)
+	lines = append(lines, Text("┌"+strings.Repeat("─", cols)+"┐"))
+	for _, line := range inner {
+		row := Text("│" + line + "│")
+		for i := 1; i < len(row)-1; i++ {
+			row[i].Style = style
+		}
+		lines = append(lines, row)
+	}
+	lines = append(lines, Text("└"+strings.Repeat("─", cols)+"┘"))
+	if p.Caption != "" {
+		lines = append(lines, Text(" "+p.Caption))
+	}
+	return lines
+}
+
+// Close puts the cursor back below the panel
+func (p *Panel) Close() {
+	p.screen.Close()
+}
+
+// pixels lets a renderer draw a framebuffer; a panel that is off shows
+// none of them
+type pixels struct {
+	fb  *display.Framebuffer
+	off bool
+}
+
+func (px pixels) Size() (int, int) {
+	w, h := px.fb.Size()
+	return int(w), int(h)
+}
+
+func (px pixels) Alive(x, y int) bool {
+	return !px.off && px.fb.GetPixel(int16(x), int16(y))
+}
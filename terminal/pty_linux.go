@@ -0,0 +1,90 @@
+package terminal
+
+import (
+	"os"
+	"strconv"
+	"syscall"
+	"unsafe"
+)
+
+// PTY is a pseudo-terminal standing in for the board's serial port: the
+// simulator reads and writes one end, and a serial monitor or the host
+// tool opens the other (Name) as it would /dev/ttyUSB0.
+//
+// Like a UART it never waits: Read returns 0 bytes when nothing has
+// arrived, and output nobody reads is dropped once the buffer is full.
+type PTY struct {
+	Name   string // path of the end to open, such as /dev/pts/3
+	master int
+	slave  *os.File // held open so output is buffered before anyone connects
+}
+
+// OpenPTY creates a pseudo-terminal in raw mode
+func OpenPTY() (*PTY, error) {
+	master, err := syscall.Open("/dev/ptmx", syscall.O_RDWR|syscall.O_NOCTTY|syscall.O_NONBLOCK|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: "/dev/ptmx", Err: err}
+	}
+	var unlock int32
+	var n uint32
+	if err := ioctl(master, syscall.TIOCSPTLCK, unsafe.Pointer(&unlock)); err != nil {
+		syscall.Close(master)
+		return nil, err
+	}
+	if err := ioctl(master, syscall.TIOCGPTN, unsafe.Pointer(&n)); err != nil {
+		syscall.Close(master)
+		return nil, err
+	}
+	name := "/dev/pts/" + strconv.Itoa(int(n))
+	slave, err := os.OpenFile(name, os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		syscall.Close(master)
+		return nil, err
+	}
+
+	// Raw: no echo (which would send the output straight back), no line
+	// editing and no newline translation, as on a UART
+	var t syscall.Termios
+	if err := ioctl(int(slave.Fd()), syscall.TCGETS, unsafe.Pointer(&t)); err != nil {
+		slave.Close()
+		syscall.Close(master)
+		return nil, err
+	}
+	t.Iflag &^= syscall.ICRNL | syscall.INLCR | syscall.IGNCR | syscall.IXON | syscall.ISTRIP
+	t.Oflag &^= syscall.OPOST
+	t.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	if err := ioctl(int(slave.Fd()), syscall.TCSETS, unsafe.Pointer(&t)); err != nil {
+		slave.Close()
+		syscall.Close(master)
+		return nil, err
+	}
+	return &PTY{Name: name, master: master, slave: slave}, nil
+}
+
+// Read returns what has arrived, without waiting
+func (p *PTY) Read(b []byte) (int, error) {
+	n, err := syscall.Read(p.master, b)
+	if err == syscall.EAGAIN || n < 0 {
+		return 0, nil
+	}
+	return n, err
+}
+
+// Write sends b, dropping what doesn't fit
+func (p *PTY) Write(b []byte) (int, error) {
+	syscall.Write(p.master, b)
+	return len(b), nil
+}
+
+// Close closes both ends
+func (p *PTY) Close() error {
+	p.slave.Close()
+	return syscall.Close(p.master)
+}
+
+func ioctl(fd int, req uint, arg unsafe.Pointer) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), uintptr(req), uintptr(arg)); errno != 0 {
+		return errno
+	}
+	return nil
+}
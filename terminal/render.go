@@ -0,0 +1,258 @@
+package terminal
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Board is a grid of cells that can be drawn on the terminal
+type Board interface {
+	Size() (width, height int)
+	Alive(x, y int) bool
+}
+
+// Cell is one character on the screen and its colour
+type Cell struct {
+	Ch    rune
+	Style string // SGR parameters such as "38;5;196", "" for the default colours
+}
+
+// Text turns a plain string into uncoloured cells
+func Text(s string) []Cell {
+	cells := make([]Cell, 0, len(s))
+	for _, c := range s {
+		cells = append(cells, Cell{Ch: c})
+	}
+	return cells
+}
+
+// Renderer draws a board as text, packing ScaleX x ScaleY cells into
+// every character.
+type Renderer struct {
+	Name   string
+	ScaleX int
+	ScaleY int
+	glyph  func(b Board, x, y int) rune // character for the block at (x, y)
+	split  bool                         // top half uses the foreground colour, bottom half the background
+	sample bool                         // only the top-left cell of each block is shown
+}
+
+var (
+	// Full draws one character per cell (needs 128x64 characters)
+	Full = Renderer{Name: "full", ScaleX: 1, ScaleY: 1, glyph: fullGlyph}
+
+	// Compact samples one cell out of every 2x2 block, dropping the rest
+	Compact = Renderer{Name: "compact", ScaleX: 2, ScaleY: 2, glyph: compactGlyph, sample: true}
+
+	// HalfBlock packs two rows into one character with ▀ ▄ █ (128x32)
+	HalfBlock = Renderer{Name: "half-block", ScaleX: 1, ScaleY: 2, glyph: halfBlockGlyph, split: true}
+
+	// Braille packs a 2x4 block into one Braille pattern (64x16)
+	Braille = Renderer{Name: "braille", ScaleX: 2, ScaleY: 4, glyph: brailleGlyph}
+)
+
+// Lossless lists the renderers that show every cell, largest first
+var Lossless = []Renderer{Full, HalfBlock, Braille}
+
+// Choose returns the largest lossless renderer whose output for a
+// width x height board fits in cols x rows characters.
+// If nothing fits, the smallest (Braille) is returned anyway.
+func Choose(cols, rows, width, height int) Renderer {
+	for _, r := range Lossless {
+		c, rr := r.TextSize(width, height)
+		if c <= cols && rr <= rows {
+			return r
+		}
+	}
+	return Braille
+}
+
+// TextSize returns how many columns and rows of text a board needs
+func (r Renderer) TextSize(width, height int) (cols, rows int) {
+	return (width + r.ScaleX - 1) / r.ScaleX, (height + r.ScaleY - 1) / r.ScaleY
+}
+
+// Layout returns where the board ends up when the first rendered line is
+// printed at screen row top, column left.
+func (r Renderer) Layout(b Board, top, left int) Layout {
+	width, height := b.Size()
+	return Layout{
+		Top: top, Left: left,
+		ScaleX: r.ScaleX, ScaleY: r.ScaleY,
+		Width: width, Height: height,
+	}
+}
+
+// Render draws the board, one string per line of text
+func (r Renderer) Render(b Board) []string {
+	width, height := b.Size()
+	cols, rows := r.TextSize(width, height)
+
+	lines := make([]string, rows)
+	var sb strings.Builder
+	for row := 0; row < rows; row++ {
+		sb.Reset()
+		for col := 0; col < cols; col++ {
+			sb.WriteRune(r.glyph(b, col*r.ScaleX, row*r.ScaleY))
+		}
+		lines[row] = sb.String()
+	}
+	return lines
+}
+
+// RenderCells draws the board in colour: cells are coloured by the palette
+// using the ages reported by h (which may be a nil *History).
+func (r Renderer) RenderCells(b Board, h Ages, p Palette) [][]Cell {
+	if !p.colored() {
+		lines := r.Render(b)
+		cells := make([][]Cell, len(lines))
+		for i, line := range lines {
+			cells[i] = Text(line)
+		}
+		return cells
+	}
+
+	// Cells that just died are drawn too, so the glyphs are worked out
+	// from what's lit rather than what's alive
+	lit := litBoard{b, h, p}
+
+	width, height := b.Size()
+	cols, rows := r.TextSize(width, height)
+	lines := make([][]Cell, rows)
+	for row := 0; row < rows; row++ {
+		line := make([]Cell, cols)
+		for col := 0; col < cols; col++ {
+			x, y := col*r.ScaleX, row*r.ScaleY
+			if r.split {
+				line[col] = r.splitCell(b, h, p, x, y)
+				continue
+			}
+
+			// The most interesting cell in the block decides the colour
+			blockX, blockY := r.ScaleX, r.ScaleY
+			if r.sample {
+				blockX, blockY = 1, 1
+			}
+			best, bestRank := Color{}, -1
+			for dy := 0; dy < blockY; dy++ {
+				for dx := 0; dx < blockX; dx++ {
+					if on, c, rank := p.shade(b, h, x+dx, y+dy); on && rank > bestRank {
+						best, bestRank = c, rank
+					}
+				}
+			}
+			line[col] = Cell{Ch: r.glyph(lit, x, y)}
+			if bestRank >= 0 {
+				line[col].Style = p.fg(best)
+			}
+		}
+		lines[row] = line
+	}
+	return lines
+}
+
+// splitCell colours a half-block character: with ▀ the top cell takes the
+// foreground colour and the bottom cell the background, so no colour is lost
+func (r Renderer) splitCell(b Board, h Ages, p Palette, x, y int) Cell {
+	topOn, top, _ := p.shade(b, h, x, y)
+	bottomOn, bottom, _ := p.shade(b, h, x, y+1)
+	switch {
+	case topOn && bottomOn && top == bottom:
+		return Cell{Ch: '█', Style: p.fg(top)}
+	case topOn && bottomOn:
+		return Cell{Ch: '▀', Style: p.fg(top) + ";" + p.bg(bottom)}
+	case topOn:
+		return Cell{Ch: '▀', Style: p.fg(top)}
+	case bottomOn:
+		return Cell{Ch: '▄', Style: p.fg(bottom)}
+	}
+	return Cell{Ch: ' '}
+}
+
+// litBoard shows live cells plus, if the theme wants them, cells that
+// died in the last generation
+type litBoard struct {
+	b Board
+	h Ages
+	p Palette
+}
+
+func (l litBoard) Size() (int, int) { return l.b.Size() }
+
+func (l litBoard) Alive(x, y int) bool {
+	on, _, _ := l.p.shade(l.b, l.h, x, y)
+	return on
+}
+
+func fullGlyph(b Board, x, y int) rune {
+	if b.Alive(x, y) {
+		return '█'
+	}
+	return ' '
+}
+
+func compactGlyph(b Board, x, y int) rune {
+	if b.Alive(x, y) {
+		return '█'
+	}
+	return '·'
+}
+
+func halfBlockGlyph(b Board, x, y int) rune {
+	top, bottom := b.Alive(x, y), b.Alive(x, y+1)
+	switch {
+	case top && bottom:
+		return '█'
+	case top:
+		return '▀'
+	case bottom:
+		return '▄'
+	}
+	return ' '
+}
+
+// brailleDots maps a cell inside the 2x4 block to its Braille dot bit
+var brailleDots = [4][2]rune{
+	{0x01, 0x08},
+	{0x02, 0x10},
+	{0x04, 0x20},
+	{0x40, 0x80},
+}
+
+func brailleGlyph(b Board, x, y int) rune {
+	glyph := rune(0x2800) // blank Braille pattern
+	for dy := 0; dy < 4; dy++ {
+		for dx := 0; dx < 2; dx++ {
+			if b.Alive(x+dx, y+dy) {
+				glyph |= brailleDots[dy][dx]
+			}
+		}
+	}
+	return glyph
+}
+
+// Size returns the terminal's size in characters.
+// If it can't be queried it falls back to $COLUMNS/$LINES, then 80x24.
+func Size() (cols, rows int) {
+	if out, err := stty("size"); err == nil {
+		// stty prints "rows cols"
+		fields := strings.Fields(out)
+		if len(fields) == 2 {
+			r, err1 := strconv.Atoi(fields[0])
+			c, err2 := strconv.Atoi(fields[1])
+			if err1 == nil && err2 == nil && r > 0 && c > 0 {
+				return c, r
+			}
+		}
+	}
+
+	cols, rows = 80, 24
+	if c, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && c > 0 {
+		cols = c
+	}
+	if r, err := strconv.Atoi(os.Getenv("LINES")); err == nil && r > 0 {
+		rows = r
+	}
+	return cols, rows
+}
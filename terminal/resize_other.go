@@ -0,0 +1,9 @@
+//go:build !unix
+
+package terminal
+
+import "os"
+
+// NotifyResize does nothing where there's no SIGWINCH; the size is still
+// picked up at start-up
+func NotifyResize(c chan<- os.Signal) {}
@@ -0,0 +1,204 @@
+package terminal
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Escape sequences used by Screen
+const (
+	clearScreen = "\033[H\033[2J"
+	clearLine   = "\033[K"
+	hideCursor  = "\033[?25l"
+	showCursor  = "\033[?25h"
+
+	// Synchronized output: the terminal holds the frame until the end
+	// marker, so a half-drawn frame is never shown
+	beginSync = "\033[?2026h"
+	endSync   = "\033[?2026l"
+)
+
+// mergeGap is how many unchanged characters we'd rather reprint than pay
+// for another cursor-positioning escape (which costs 6-8 bytes)
+const mergeGap = 4
+
+// Screen redraws the terminal by sending only the characters that changed
+// since the previous frame.
+type Screen struct {
+	w     io.Writer
+	prev  [][]Cell // last frame drawn, nil forces a full redraw
+	sync  bool
+	buf   bytes.Buffer
+	pen   string // style the terminal is currently drawing with
+	bytes int    // bytes written for the last frame
+}
+
+// NewScreen creates a screen that draws to w, using synchronized output
+// if the terminal supports it
+func NewScreen(w io.Writer) *Screen {
+	return &Screen{w: w, sync: SyncSupported()}
+}
+
+// Draw shows a frame, one line of cells per screen row starting at the top
+func (s *Screen) Draw(frame [][]Cell) error {
+	s.buf.Reset()
+	if s.sync {
+		s.buf.WriteString(beginSync)
+	}
+
+	if s.prev == nil {
+		// First frame (or after Invalidate): draw everything
+		s.buf.WriteString(hideCursor)
+		s.setPen("")
+		s.buf.WriteString(clearScreen)
+		for i, row := range frame {
+			if i > 0 {
+				s.setPen("")
+				s.buf.WriteString("\r\n")
+			}
+			s.write(row)
+		}
+	} else {
+		for i, row := range frame {
+			var old []Cell
+			if i < len(s.prev) {
+				old = s.prev[i]
+			}
+			s.diffRow(i, old, row)
+		}
+		// Blank out rows the previous frame had and this one doesn't
+		for i := len(frame); i < len(s.prev); i++ {
+			if len(s.prev[i]) > 0 {
+				s.moveTo(i, 0)
+				s.setPen("")
+				s.buf.WriteString(clearLine)
+			}
+		}
+	}
+	s.setPen("")
+
+	if s.sync {
+		s.buf.WriteString(endSync)
+	}
+	// Keep our own copy, the caller may reuse its slices
+	s.prev = make([][]Cell, len(frame))
+	for i, row := range frame {
+		s.prev[i] = append([]Cell(nil), row...)
+	}
+	s.bytes = s.buf.Len()
+
+	_, err := s.w.Write(s.buf.Bytes())
+	return err
+}
+
+// diffRow writes the changed runs of characters in one row
+func (s *Screen) diffRow(row int, old, cur []Cell) {
+	col := 0
+	for col < len(cur) {
+		if col < len(old) && old[col] == cur[col] {
+			col++
+			continue
+		}
+
+		// Extend the run while characters differ, bridging short gaps
+		start, end := col, col+1
+		for end < len(cur) {
+			if end >= len(old) || old[end] != cur[end] {
+				end++
+				continue
+			}
+			gap := end
+			for gap < len(cur) && gap < len(old) && old[gap] == cur[gap] && gap-end < mergeGap {
+				gap++
+			}
+			if gap < len(cur) && gap-end < mergeGap {
+				end = gap // short gap followed by more changes
+				continue
+			}
+			break
+		}
+
+		s.moveTo(row, start)
+		s.write(cur[start:end])
+		col = end
+	}
+
+	// The row got shorter: erase what's left of the old one
+	if len(old) > len(cur) {
+		s.moveTo(row, len(cur))
+		s.setPen("")
+		s.buf.WriteString(clearLine)
+	}
+}
+
+// write outputs cells, switching colours only where the style changes
+func (s *Screen) write(cells []Cell) {
+	for _, c := range cells {
+		s.setPen(c.Style)
+		s.buf.WriteRune(c.Ch)
+	}
+}
+
+// setPen switches the terminal to a style
+func (s *Screen) setPen(style string) {
+	if style == s.pen {
+		return
+	}
+	if style == "" {
+		s.buf.WriteString("\033[0m")
+	} else {
+		s.buf.WriteString("\033[0;" + style + "m")
+	}
+	s.pen = style
+}
+
+// moveTo positions the cursor at a 0-based row and column
+func (s *Screen) moveTo(row, col int) {
+	fmt.Fprintf(&s.buf, "\033[%d;%dH", row+1, col+1)
+}
+
+// Invalidate forgets the previous frame so the next Draw repaints the
+// whole screen (after a resize, or when something else wrote to it)
+func (s *Screen) Invalidate() {
+	s.prev = nil
+}
+
+// FrameBytes returns how many bytes the last Draw sent to the terminal
+func (s *Screen) FrameBytes() int {
+	return s.bytes
+}
+
+// Close puts the cursor back below the last frame and makes it visible
+func (s *Screen) Close() {
+	fmt.Fprintf(s.w, "\033[%d;1H\r\n%s", len(s.prev), showCursor)
+}
+
+// SyncSupported reports whether the terminal is known to understand
+// synchronized output (mode 2026).
+// Set GOL_SYNC_OUTPUT=1 or 0 to override the guess.
+func SyncSupported() bool {
+	switch os.Getenv("GOL_SYNC_OUTPUT") {
+	case "1":
+		return true
+	case "0":
+		return false
+	}
+
+	switch os.Getenv("TERM_PROGRAM") {
+	case "iTerm.app", "WezTerm", "ghostty", "vscode", "contour", "rio":
+		return true
+	}
+	if os.Getenv("KITTY_WINDOW_ID") != "" || os.Getenv("WT_SESSION") != "" {
+		return true
+	}
+	term := os.Getenv("TERM")
+	for _, name := range []string{"kitty", "foot", "alacritty", "wezterm", "ghostty", "contour"} {
+		if strings.Contains(term, name) {
+			return true
+		}
+	}
+	return false
+}
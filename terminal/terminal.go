@@ -0,0 +1,36 @@
+// Package terminal holds the pieces of the desktop terminal UI that are not
+// specific to one program: raw keyboard/mouse input, renderers that turn a
+// board into text, and mapping the screen back to board cells.
+package terminal
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// MakeRaw switches stdin to unbuffered, no-echo mode so single key presses
+// and mouse reports can be read as they arrive.
+// Output processing and Ctrl+C are left alone, so fmt.Println and
+// interrupting the program keep working.
+// The returned function restores the previous terminal settings.
+func MakeRaw() (restore func(), err error) {
+	saved, err := stty("-g")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := stty("-icanon", "-echo", "min", "1"); err != nil {
+		return nil, err
+	}
+	return func() {
+		stty(strings.TrimSpace(saved))
+	}, nil
+}
+
+// stty runs the stty command against the terminal attached to stdin
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return string(out), err
+}
@@ -0,0 +1,201 @@
+package terminal
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ColorMode is how many colours the terminal can show
+type ColorMode int
+
+const (
+	Mono      ColorMode = iota // no colour escapes at all
+	Color256                   // xterm 256-colour palette
+	TrueColor                  // 24-bit RGB
+)
+
+// DetectColorMode picks the colour mode for output to f.
+// NO_COLOR (https://no-color.org) and output that isn't a terminal always
+// get Mono.
+func DetectColorMode(f *os.File) ColorMode {
+	if os.Getenv("NO_COLOR") != "" {
+		return Mono
+	}
+	if info, err := f.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return Mono // piped or redirected
+	}
+	switch os.Getenv("COLORTERM") {
+	case "truecolor", "24bit":
+		return TrueColor
+	}
+	if strings.Contains(os.Getenv("TERM"), "256color") {
+		return Color256
+	}
+	return Mono
+}
+
+// Color is an RGB colour
+type Color struct {
+	R, G, B uint8
+}
+
+// index256 returns the nearest colour in the xterm 6x6x6 colour cube
+func (c Color) index256() int {
+	level := func(v uint8) int { return (int(v)*5 + 127) / 255 }
+	return 16 + 36*level(c.R) + 6*level(c.G) + level(c.B)
+}
+
+// Theme colours live cells by how many generations they have survived,
+// and can show cells that died in the last generation.
+type Theme struct {
+	Name     string
+	Born     Color   // came alive this generation
+	Ages     []Color // colour for age 2, 3, ...; the last one is used for anything older
+	Died     Color   // died this generation
+	ShowDied bool
+}
+
+// Themes are the built-in colour themes, "mono" first
+var Themes = []Theme{
+	{Name: "mono"},
+	{
+		Name: "heat",
+		Born: Color{255, 255, 220},
+		Ages: []Color{
+			{255, 230, 80}, {255, 190, 40}, {255, 140, 20},
+			{240, 90, 20}, {210, 50, 30}, {160, 30, 40},
+		},
+		Died:     Color{60, 60, 110},
+		ShowDied: true,
+	},
+	{
+		Name: "ocean",
+		Born: Color{220, 255, 255},
+		Ages: []Color{
+			{120, 240, 255}, {60, 200, 255}, {40, 150, 240},
+			{40, 100, 220}, {40, 60, 180},
+		},
+		Died:     Color{70, 70, 70},
+		ShowDied: true,
+	},
+	{
+		Name: "matrix",
+		Born: Color{210, 255, 210},
+		Ages: []Color{
+			{120, 255, 120}, {60, 220, 60}, {30, 170, 30}, {20, 120, 20},
+		},
+		Died:     Color{20, 60, 20},
+		ShowDied: true,
+	},
+}
+
+// ThemeByName looks up a built-in theme, falling back to mono
+func ThemeByName(name string) Theme {
+	for _, t := range Themes {
+		if t.Name == name {
+			return t
+		}
+	}
+	return Themes[0]
+}
+
+// Palette is a theme resolved for a colour mode
+type Palette struct {
+	Theme Theme
+	Mode  ColorMode
+}
+
+// colored reports whether the palette produces any colour at all
+func (p Palette) colored() bool {
+	return p.Mode != Mono && len(p.Theme.Ages) > 0
+}
+
+// fg and bg return the SGR parameters that select c
+func (p Palette) fg(c Color) string { return p.sgr(38, c) }
+func (p Palette) bg(c Color) string { return p.sgr(48, c) }
+
+func (p Palette) sgr(base int, c Color) string {
+	if p.Mode == TrueColor {
+		return fmt.Sprintf("%d;2;%d;%d;%d", base, c.R, c.G, c.B)
+	}
+	return fmt.Sprintf("%d;5;%d", base, c.index256())
+}
+
+// shade works out how a cell is drawn: whether it's lit at all, its colour,
+// and a rank so the most interesting cell of a block wins (newborn cells
+// rank highest, then younger cells, then cells that just died).
+func (p Palette) shade(b Board, h Ages, x, y int) (lit bool, c Color, rank int) {
+	if b.Alive(x, y) {
+		age := h.Age(x, y)
+		if age <= 1 {
+			return true, p.Theme.Born, 1 << 16
+		}
+		ages := p.Theme.Ages
+		return true, ages[min(age-2, len(ages)-1)], 1<<16 - age
+	}
+	if p.Theme.ShowDied && h.Died(x, y) {
+		return true, p.Theme.Died, 0
+	}
+	return false, Color{}, -1
+}
+
+// Ages reports how long cells have been alive; History is the usual one
+type Ages interface {
+	Age(x, y int) int
+	Died(x, y int) bool
+}
+
+// History remembers how many generations each cell has been alive and
+// which cells died in the last generation.
+type History struct {
+	width, height int
+	age           []uint16
+	died          []bool
+}
+
+// Update records a new generation; call it once after every step
+func (h *History) Update(b Board) {
+	width, height := b.Size()
+	if width != h.width || height != h.height {
+		h.width, h.height = width, height
+		h.age = make([]uint16, width*height)
+		h.died = make([]bool, width*height)
+	}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			i := y*width + x
+			alive := b.Alive(x, y)
+			h.died[i] = !alive && h.age[i] > 0
+			switch {
+			case !alive:
+				h.age[i] = 0
+			case h.age[i] < 0xFFFF:
+				h.age[i]++
+			}
+		}
+	}
+}
+
+// Reset forgets all ages, e.g. after loading a new board
+func (h *History) Reset() {
+	clear(h.age)
+	clear(h.died)
+}
+
+// Age returns how many generations the cell at (x, y) has been alive;
+// 1 means it was born in the last generation, 0 means unknown or dead.
+func (h *History) Age(x, y int) int {
+	if h == nil || x < 0 || x >= h.width || y < 0 || y >= h.height {
+		return 0
+	}
+	return int(h.age[y*h.width+x])
+}
+
+// Died reports whether the cell at (x, y) died in the last generation
+func (h *History) Died(x, y int) bool {
+	if h == nil || x < 0 || x >= h.width || y < 0 || y >= h.height {
+		return false
+	}
+	return h.died[y*h.width+x]
+}
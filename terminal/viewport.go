@@ -0,0 +1,219 @@
+package terminal
+
+// Zoom levels a Viewport steps through: positive levels magnify (each board
+// cell becomes n x n view cells), negative levels shrink (each view cell
+// shows an n x n block of board cells, lit if any of them is alive).
+var zoomLevels = []int{-4, -2, 1, 2, 4, 8}
+
+// Viewport is a movable, zoomable window onto a board. It is a Board
+// itself, so any renderer can draw it.
+// The board wraps around at the edges (like the Game of Life grid), so
+// panning past an edge continues from the other side.
+type Viewport struct {
+	Board Board
+	X, Y  int // board cell shown at the top-left of the view
+	zoom  int // index into zoomLevels
+
+	// Requested view size in view cells; the actual size is clamped so the
+	// view never shows the board more than once
+	width, height int
+}
+
+// NewViewport creates a 1:1 view of the whole board
+func NewViewport(b Board) *Viewport {
+	v := &Viewport{Board: b}
+	v.Reset()
+	return v
+}
+
+// Reset goes back to 1:1 zoom with the board's top-left corner in view
+func (v *Viewport) Reset() {
+	v.X, v.Y = 0, 0
+	for i, z := range zoomLevels {
+		if z == 1 {
+			v.zoom = i
+		}
+	}
+}
+
+// Zoom returns the current zoom level (see zoomLevels)
+func (v *Viewport) Zoom() int {
+	return zoomLevels[v.zoom]
+}
+
+// Resize sets how many view cells fit on screen
+func (v *Viewport) Resize(width, height int) {
+	v.width, v.height = width, height
+}
+
+// Size returns the view size in view cells
+func (v *Viewport) Size() (int, int) {
+	bw, bh := v.ZoomedSize()
+	w, h := bw, bh
+	if v.width > 0 {
+		w = min(w, v.width)
+	}
+	if v.height > 0 {
+		h = min(h, v.height)
+	}
+	return w, h
+}
+
+// ZoomedSize returns the size the whole board would take at this zoom
+func (v *Viewport) ZoomedSize() (int, int) {
+	bw, bh := v.Board.Size()
+	if z := v.Zoom(); z < 0 {
+		return (bw - z - 1) / -z, (bh - z - 1) / -z
+	}
+	return bw * v.Zoom(), bh * v.Zoom()
+}
+
+// ToBoard converts a view cell to the board cell it shows (the top-left
+// one when zoomed out)
+func (v *Viewport) ToBoard(vx, vy int) (x, y int) {
+	bw, bh := v.Board.Size()
+	if z := v.Zoom(); z > 0 {
+		x, y = v.X+vx/z, v.Y+vy/z
+	} else {
+		x, y = v.X+vx*-z, v.Y+vy*-z
+	}
+	return wrap(x, bw), wrap(y, bh)
+}
+
+// inView reports whether (vx, vy) is inside the view; renderers may ask
+// about cells past the edge when the size isn't a multiple of their block
+func (v *Viewport) inView(vx, vy int) bool {
+	w, h := v.Size()
+	return vx >= 0 && vx < w && vy >= 0 && vy < h
+}
+
+// Alive reports whether a view cell is lit
+func (v *Viewport) Alive(vx, vy int) bool {
+	if !v.inView(vx, vy) {
+		return false
+	}
+	x, y := v.ToBoard(vx, vy)
+	if v.Zoom() > 0 {
+		return v.Board.Alive(x, y)
+	}
+
+	// Zoomed out: lit if anything in the block is alive
+	bw, bh := v.Board.Size()
+	n := -v.Zoom()
+	for dy := 0; dy < n; dy++ {
+		for dx := 0; dx < n; dx++ {
+			if v.Board.Alive(wrap(x+dx, bw), wrap(y+dy, bh)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Pan moves the view by dx, dy view cells
+func (v *Viewport) Pan(dx, dy int) {
+	bw, bh := v.Board.Size()
+	v.X, v.Y = wrap(v.X+v.boardCells(dx), bw), wrap(v.Y+v.boardCells(dy), bh)
+}
+
+// boardCells converts a distance in view cells to board cells, moving at
+// least one cell when magnified
+func (v *Viewport) boardCells(d int) int {
+	z := v.Zoom()
+	if z < 0 {
+		return d * -z
+	}
+	if n := d / z; n != 0 || d == 0 {
+		return n
+	}
+	if d < 0 {
+		return -1
+	}
+	return 1
+}
+
+// ZoomIn magnifies one level, keeping the centre of the view in place
+func (v *Viewport) ZoomIn() {
+	if v.zoom < len(zoomLevels)-1 {
+		v.zoomAroundCentre(v.zoom + 1)
+	}
+}
+
+// ZoomOut shrinks one level, keeping the centre of the view in place
+func (v *Viewport) ZoomOut() {
+	if v.zoom > 0 {
+		v.zoomAroundCentre(v.zoom - 1)
+	}
+}
+
+func (v *Viewport) zoomAroundCentre(level int) {
+	w, h := v.Size()
+	cx, cy := v.ToBoard(w/2, h/2)
+
+	v.zoom = level
+	w, h = v.Size()
+	bw, bh := v.Board.Size()
+	v.X, v.Y = wrap(cx-v.boardCells(w/2), bw), wrap(cy-v.boardCells(h/2), bh)
+}
+
+// Ages maps cell ages from board coordinates into the view, for colouring
+func (v *Viewport) Ages(h Ages) Ages {
+	return viewAges{v, h}
+}
+
+type viewAges struct {
+	v *Viewport
+	h Ages
+}
+
+// Age returns the youngest living cell's age when zoomed out, so the
+// newest activity shows through
+func (a viewAges) Age(vx, vy int) int {
+	if !a.v.inView(vx, vy) {
+		return 0
+	}
+	x, y := a.v.ToBoard(vx, vy)
+	if a.v.Zoom() > 0 {
+		return a.h.Age(x, y)
+	}
+	bw, bh := a.v.Board.Size()
+	n := -a.v.Zoom()
+	youngest := 0
+	for dy := 0; dy < n; dy++ {
+		for dx := 0; dx < n; dx++ {
+			age := a.h.Age(wrap(x+dx, bw), wrap(y+dy, bh))
+			if age > 0 && (youngest == 0 || age < youngest) {
+				youngest = age
+			}
+		}
+	}
+	return youngest
+}
+
+func (a viewAges) Died(vx, vy int) bool {
+	if !a.v.inView(vx, vy) {
+		return false
+	}
+	x, y := a.v.ToBoard(vx, vy)
+	if a.v.Zoom() > 0 {
+		return a.h.Died(x, y)
+	}
+	bw, bh := a.v.Board.Size()
+	n := -a.v.Zoom()
+	for dy := 0; dy < n; dy++ {
+		for dx := 0; dx < n; dx++ {
+			if a.h.Died(wrap(x+dx, bw), wrap(y+dy, bh)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// wrap brings n into [0, size)
+func wrap(n, size int) int {
+	if size <= 0 {
+		return 0
+	}
+	return ((n % size) + size) % size
+}
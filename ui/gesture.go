@@ -0,0 +1,187 @@
+package ui
+
+import (
+	"time"
+
+	"gameoflife/input"
+)
+
+// Gesture is something the user did with a single button
+type Gesture int
+
+const (
+	NoGesture   Gesture = iota
+	Click               // one short press, confirmed once no second press follows
+	DoubleClick         // two short presses
+	TripleClick         // three short presses
+	LongPress           // held for GestureConfig.LongPress
+	Repeat              // still held after a long press, every GestureConfig.RepeatInterval
+	ClickHold           // a click and then a long press straight after it
+)
+
+// String names the gesture for log output
+func (g Gesture) String() string {
+	switch g {
+	case Click:
+		return "click"
+	case DoubleClick:
+		return "double click"
+	case TripleClick:
+		return "triple click"
+	case LongPress:
+		return "long press"
+	case Repeat:
+		return "repeat"
+	case ClickHold:
+		return "click and hold"
+	}
+	return "none"
+}
+
+// GestureConfig holds the timings a GestureDetector works with
+type GestureConfig struct {
+	// Debounce is how long after a change the button is ignored, so
+	// contact bounce doesn't read as extra presses. Changes are accepted
+	// straight away otherwise, which keeps short taps from being lost
+	// when the button is only polled every 100ms.
+	Debounce time.Duration
+	// MultiClick is how long after a release another press still counts
+	// towards a double or triple click. A single click is only reported
+	// once this has passed, so a double click never starts as a click.
+	MultiClick time.Duration
+	// LongPress is how long the button must be held for a long press
+	LongPress time.Duration
+	// RepeatInterval is how often Repeat fires while the button is held
+	// after a long press; 0 turns repeating off
+	RepeatInterval time.Duration
+}
+
+// DefaultGestures suits a tactile switch polled every 50-100ms
+var DefaultGestures = GestureConfig{
+	Debounce:       20 * time.Millisecond,
+	MultiClick:     350 * time.Millisecond,
+	LongPress:      600 * time.Millisecond,
+	RepeatInterval: 200 * time.Millisecond,
+}
+
+// GestureDetector turns the raw button state into clicks, multi-clicks,
+// long presses and hold-to-repeat. Unlike ClickDetector it debounces the
+// input and holds a click back until it knows it isn't the start of a
+// double click.
+type GestureDetector struct {
+	config GestureConfig
+	clock  input.Clock
+
+	held      bool      // debounced state
+	changedAt time.Time // when held last changed
+
+	pressedAt  time.Time // when the current press started
+	releasedAt time.Time // when the last short press ended
+	clicks     int       // short presses waiting to be reported
+	long       bool      // the current press has become a long press (or was cancelled)
+	repeating  bool      // the long press fires Repeat
+	nextRepeat time.Time
+}
+
+// NewGestureDetector creates a detector using the real clock
+func NewGestureDetector(config GestureConfig) *GestureDetector {
+	return NewGestureDetectorClock(config, input.SystemClock)
+}
+
+// NewGestureDetectorClock creates a detector that times presses with clock
+// (an input.Script in tests)
+func NewGestureDetectorClock(config GestureConfig, clock input.Clock) *GestureDetector {
+	return &GestureDetector{config: config, clock: clock}
+}
+
+// Update takes the current button state and returns the gesture it
+// completes, if any. Call it regularly, pressed or not: clicks are only
+// confirmed and repeats only fire from inside Update.
+func (gd *GestureDetector) Update(pressed bool) Gesture {
+	now := gd.clock.Now()
+
+	// Debounce: changes right after the last one are bounce
+	if pressed != gd.held && now.Sub(gd.changedAt) >= gd.config.Debounce {
+		gd.held = pressed
+		gd.changedAt = now
+		if gd.held {
+			return gd.press(now)
+		}
+		return gd.release(now)
+	}
+
+	if gd.held {
+		return gd.holding(now)
+	}
+
+	// Released: once no further press can follow, report the clicks
+	if gd.clicks > 0 && now.Sub(gd.releasedAt) >= gd.config.MultiClick {
+		return gd.flush()
+	}
+	return NoGesture
+}
+
+func (gd *GestureDetector) press(now time.Time) Gesture {
+	gd.pressedAt = now
+	gd.long = false
+	return NoGesture
+}
+
+func (gd *GestureDetector) release(now time.Time) Gesture {
+	if gd.long {
+		return NoGesture // the long press was already reported
+	}
+	gd.clicks++
+	gd.releasedAt = now
+	if gd.clicks == 3 {
+		return gd.flush() // nothing counts beyond a triple click
+	}
+	return NoGesture
+}
+
+func (gd *GestureDetector) holding(now time.Time) Gesture {
+	if !gd.long {
+		if now.Sub(gd.pressedAt) < gd.config.LongPress {
+			return NoGesture
+		}
+		gd.long = true
+		if gd.clicks > 0 {
+			// Pressed again within MultiClick of a click: the clicks
+			// and the hold are one gesture, which doesn't repeat
+			gd.clicks = 0
+			gd.repeating = false
+			return ClickHold
+		}
+		gd.repeating = true
+		gd.nextRepeat = now.Add(gd.config.RepeatInterval)
+		return LongPress
+	}
+	if gd.repeating && gd.config.RepeatInterval > 0 && !now.Before(gd.nextRepeat) {
+		gd.nextRepeat = now.Add(gd.config.RepeatInterval)
+		return Repeat
+	}
+	return NoGesture
+}
+
+// Cancel drops the press in progress and any clicks waiting to be
+// reported, for when the press was part of something else (a chord with
+// another button, or pushing while turning an encoder). Its release won't
+// be a click and it won't become a long press.
+func (gd *GestureDetector) Cancel() {
+	gd.clicks = 0
+	gd.long = true
+	gd.repeating = false
+}
+
+// flush reports the waiting clicks as one gesture
+func (gd *GestureDetector) flush() Gesture {
+	n := gd.clicks
+	gd.clicks = 0
+	switch n {
+	case 1:
+		return Click
+	case 2:
+		return DoubleClick
+	}
+	return TripleClick
+}
@@ -0,0 +1,118 @@
+package ui
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"gameoflife/input"
+)
+
+// seen is a gesture and when it was reported
+type seen struct {
+	g  Gesture
+	at time.Duration
+}
+
+func (s seen) String() string { return fmt.Sprintf("%v@%v", s.g, s.at) }
+
+// gestures runs a detector over the script, polling every step until a
+// second after its last edge
+func gestures(s *input.Script, step time.Duration) []seen {
+	gd := NewGestureDetectorClock(DefaultGestures, s)
+	var out []seen
+	for s.Elapsed() <= s.End()+time.Second {
+		if g := gd.Update(s.Pressed()); g != NoGesture {
+			out = append(out, seen{g, s.Elapsed()})
+		}
+		s.Advance(step)
+	}
+	return out
+}
+
+func ms(n int) time.Duration { return time.Duration(n) * time.Millisecond }
+
+func TestClickDetectorDoubleClick(t *testing.T) {
+	tests := []struct {
+		name   string
+		script *input.Script
+		want   string // s for a single click, d for a double, per release
+	}{
+		{"press 0, release 80, press 200", input.NewScript().Press(0).Release(ms(80)).Press(ms(200)).Release(ms(260)), "sd"},
+		{"releases 399ms apart", input.NewScript().Click(0, ms(80)).Click(ms(400), ms(79)), "sd"},
+		{"releases 400ms apart", input.NewScript().Click(0, ms(80)).Click(ms(400), ms(80)), "ss"},
+		{"three quick clicks", input.NewScript().Click(0, ms(50)).Click(ms(100), ms(50)).Click(ms(200), ms(50)), "sds"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := tt.script
+			cd := NewClickDetectorClock(s)
+			got := ""
+			for s.Elapsed() <= s.End()+time.Second {
+				single, double := cd.CheckClick(s.Pressed())
+				if single {
+					got += "s"
+				}
+				if double {
+					got += "d"
+				}
+				s.Advance(ms(1))
+			}
+			if got != tt.want {
+				t.Errorf("%v: got %q, want %q", s, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGestureDetector(t *testing.T) {
+	tests := []struct {
+		name   string
+		script *input.Script
+		want   []Gesture
+	}{
+		{"click", input.NewScript().Click(0, ms(80)), []Gesture{Click}},
+		{"double click", input.NewScript().Press(0).Release(ms(80)).Press(ms(200)).Release(ms(260)), []Gesture{DoubleClick}},
+		{"triple click", input.NewScript().Click(0, ms(60)).Click(ms(150), ms(60)).Click(ms(300), ms(60)), []Gesture{TripleClick}},
+
+		// MultiClick is 350ms: a press on the last poll inside it still counts
+		{"second press at the end of the window", input.NewScript().Click(0, ms(80)).Click(ms(430), ms(80)), []Gesture{DoubleClick}},
+		{"second press just after the window", input.NewScript().Click(0, ms(80)).Click(ms(440), ms(80)), []Gesture{Click, Click}},
+
+		// LongPress is 600ms
+		{"held just short of a long press", input.NewScript().Click(0, ms(590)), []Gesture{Click}},
+		{"held for a long press", input.NewScript().Click(0, ms(610)), []Gesture{LongPress}},
+		{"held on, repeating", input.NewScript().Click(0, ms(1010)), []Gesture{LongPress, Repeat, Repeat}},
+		{"click and hold", input.NewScript().Click(0, ms(80)).Click(ms(200), ms(700)), []Gesture{ClickHold}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := gestures(tt.script, ms(10))
+			if len(got) != len(tt.want) {
+				t.Fatalf("%v: got %v, want %v", tt.script, got, tt.want)
+			}
+			for i := range got {
+				if got[i].g != tt.want[i] {
+					t.Fatalf("%v: got %v, want %v", tt.script, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// A click is held back until no second press can follow
+func TestGestureDetectorClickWaitsForMultiClick(t *testing.T) {
+	got := gestures(input.NewScript().Click(0, ms(80)), ms(10))
+	if len(got) != 1 || got[0].at != ms(80)+DefaultGestures.MultiClick {
+		t.Errorf("got %v, want a click at %v", got, ms(80)+DefaultGestures.MultiClick)
+	}
+}
+
+// Bounce within Debounce of a change isn't another press
+func TestGestureDetectorDebounce(t *testing.T) {
+	s := input.NewScript().Press(0).Release(ms(5)).Press(ms(10)).Release(ms(100)).Press(ms(105)).Release(ms(110))
+	got := gestures(s, ms(1))
+	if len(got) != 1 || got[0].g != Click {
+		t.Errorf("%v: got %v, want one click", s, got)
+	}
+}
@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"strings"
+	"time"
+
+	"gameoflife/display"
+	"gameoflife/input"
+)
+
+// HUDMode is how a HUD is shown over a game
+type HUDMode int
+
+const (
+	HUDOff    HUDMode = iota
+	HUDStrip          // a status line kept at the bottom of the screen
+	HUDBanner         // a box at the top, shown for a few seconds at a time
+)
+
+// HUDModes names the modes, in order, for a menu Choice
+var HUDModes = []string{"Off", "Strip", "Banner"}
+
+// String names the mode for log output
+func (m HUDMode) String() string {
+	if m < 0 || int(m) >= len(HUDModes) {
+		return "?"
+	}
+	return HUDModes[m]
+}
+
+// HUD layout
+const (
+	hudStripHeight  = 9  // one line of text and the line above it
+	hudBannerHeight = 19 // two lines of text
+)
+
+// HUD is a status overlay drawn on top of a game after the game has drawn
+// its frame and before the display is flushed. The strip hides the cells
+// underneath it; the banner only covers them while it is up.
+type HUD struct {
+	Mode       HUDMode
+	BannerTime time.Duration // how long the banner stays up
+
+	clock   input.Clock
+	last    HUDMode   // what Toggle turns back on
+	shownAt time.Time // when the banner was last put up
+}
+
+// NewHUD creates a HUD in mode using the real clock
+func NewHUD(mode HUDMode) *HUD {
+	return NewHUDClock(mode, input.SystemClock)
+}
+
+// NewHUDClock creates a HUD that times the banner with clock
+func NewHUDClock(mode HUDMode, clock input.Clock) *HUD {
+	h := &HUD{Mode: mode, BannerTime: 3 * time.Second, clock: clock, last: mode}
+	if mode == HUDOff {
+		h.last = HUDStrip
+	}
+	h.Flash()
+	return h
+}
+
+// Toggle turns the HUD off, or back on in the mode it had before
+func (h *HUD) Toggle() {
+	if h.Mode == HUDOff {
+		h.Mode = h.last
+		h.Flash()
+		return
+	}
+	h.last = h.Mode
+	h.Mode = HUDOff
+}
+
+// Flash puts the banner up again, for when something it shows has
+// changed (a new pattern, say)
+func (h *HUD) Flash() {
+	h.shownAt = h.clock.Now()
+}
+
+// Draw draws the HUD, if it is on: title (such as the pattern name) and
+// stats, short fields like "G120", joined with spaces
+func (h *HUD) Draw(d display.Display, title string, stats ...string) {
+	w, ht := d.Size()
+	line := strings.Join(stats, " ")
+
+	switch h.Mode {
+	case HUDStrip:
+		// Stats on the left; the title on the right if it fits (the
+		// banner always has room for it)
+		r := Rect{0, ht - hudStripHeight, w, hudStripHeight}
+		FillRect(d, r, false)
+		FillRect(d, Rect{0, r.Y, w, 1}, true)
+		text := Rect{1, r.Y + 1, w - 2, r.H - 1}
+		drawTextIn(d, line, text, AlignLeft, false)
+		room := text.W - TextWidth(line) - Font5x7.Advance
+		if TextWidth(title) <= room {
+			drawTextIn(d, title, text, AlignRight, false)
+		}
+
+	case HUDBanner:
+		if h.clock.Now().Sub(h.shownAt) >= h.BannerTime {
+			return
+		}
+		r := Rect{0, 0, w, hudBannerHeight}
+		FillRect(d, r, true)
+		FillRect(d, Rect{0, r.H, w, 1}, false) // keep it apart from the cells
+		half := r.H / 2
+		drawTextIn(d, Truncate(title, w-4), Rect{2, 1, w - 4, half}, AlignCenter, true)
+		drawTextIn(d, Truncate(line, w-4), Rect{2, half, w - 4, half}, AlignCenter, true)
+	}
+}
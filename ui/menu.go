@@ -0,0 +1,315 @@
+package ui
+
+import (
+	"strconv"
+
+	"gameoflife/display"
+)
+
+// Menu layout on a 128x64 display
+const (
+	menuWidth       = 128
+	menuHeight      = 64
+	menuTitleHeight = 10 // title and the line under it
+	menuRowHeight   = 10
+	menuBarWidth    = 122 // selection bar; the scroll indicator is to its right
+)
+
+// Menu is a scrolling list of items with a title. Items can open
+// submenus, run actions or edit values; the Navigator events move through
+// it:
+//
+//	next/prev  move the selection bar (or change the value being edited)
+//	select     open, run, toggle, or start/stop editing a value
+//	back       leave the value or submenu, or the menu itself
+type Menu struct {
+	Title string
+	Items []Item
+
+	selected int
+	top      int   // first item on screen
+	editing  bool  // next/prev change the selected item's value
+	open     *Menu // submenu being shown
+}
+
+// Item is one line of a Menu. Set one of Submenu, Value, Action or Back;
+// Exit may be combined with Action.
+type Item struct {
+	Label   string
+	Submenu *Menu  // select opens it
+	Value   Value  // shown on the right; select edits it
+	Action  func() // select runs it
+	Exit    bool   // after the action, close every menu level (MenuDone)
+	Back    bool   // select goes back, like the back event
+}
+
+// MenuResult says what happened to a Menu after an event
+type MenuResult int
+
+const (
+	MenuOpen MenuResult = iota // still showing
+	MenuBack                   // back was used on the top level
+	MenuDone                   // an Exit item was chosen
+)
+
+// NewListMenu creates a menu of plain labels, for picking one of them
+func NewListMenu(title string, labels []string) *Menu {
+	m := &Menu{Title: title}
+	for _, label := range labels {
+		m.Items = append(m.Items, Item{Label: label})
+	}
+	return m
+}
+
+// Selected returns the index of the highlighted item
+func (m *Menu) Selected() int {
+	return m.selected
+}
+
+// SetSelected highlights item i
+func (m *Menu) SetSelected(i int) {
+	if i >= 0 && i < len(m.Items) {
+		m.selected = i
+	}
+}
+
+// Reset closes any submenu and stops editing, leaving the selection alone
+func (m *Menu) Reset() {
+	if m.open != nil {
+		m.open.Reset()
+	}
+	m.open = nil
+	m.editing = false
+}
+
+// Handle applies a navigation event to the menu (or the submenu that is
+// open inside it)
+func (m *Menu) Handle(ev Nav) MenuResult {
+	if m.open != nil {
+		switch m.open.Handle(ev) {
+		case MenuBack:
+			m.open = nil
+		case MenuDone:
+			m.open = nil
+			return MenuDone
+		}
+		return MenuOpen
+	}
+	if len(m.Items) == 0 {
+		if ev == NavBack {
+			return MenuBack
+		}
+		return MenuOpen
+	}
+
+	item := &m.Items[m.selected]
+	if m.editing {
+		switch ev {
+		case NavNext:
+			item.Value.Adjust(1)
+		case NavPrev:
+			item.Value.Adjust(-1)
+		case NavSelect, NavBack:
+			m.editing = false
+		}
+		return MenuOpen
+	}
+
+	switch ev {
+	case NavNext:
+		m.selected = (m.selected + 1) % len(m.Items)
+	case NavPrev:
+		m.selected = (m.selected + len(m.Items) - 1) % len(m.Items)
+	case NavBack:
+		return MenuBack
+	case NavSelect:
+		switch {
+		case item.Back:
+			return MenuBack
+		case item.Submenu != nil:
+			m.open = item.Submenu
+		case item.Value != nil:
+			if t, ok := item.Value.(*Toggle); ok {
+				t.Adjust(1) // nothing to edit, just flip it
+			} else {
+				m.editing = true
+			}
+		default:
+			if item.Action != nil {
+				item.Action()
+			}
+			if item.Exit {
+				return MenuDone
+			}
+		}
+	}
+	return MenuOpen
+}
+
+// menuSize is the part of the display the menu is drawn on: all of a
+// single panel, and the top left panel of a tiled display
+func menuSize(display display.Display) (w, h int16) {
+	w, h = display.Size()
+	return min(w, menuWidth), min(h, menuHeight)
+}
+
+// visibleRows is how many items fit under the title
+func visibleRows(display display.Display) int {
+	_, h := menuSize(display)
+	return int((h - menuTitleHeight) / menuRowHeight)
+}
+
+// Show draws the menu (or the open submenu) and sends it to the display
+func (m *Menu) Show(display display.Display) {
+	if m.open != nil {
+		m.open.Show(display)
+		return
+	}
+	display.ClearBuffer()
+	w, _ := menuSize(display)
+
+	// Title with a line under it
+	DrawTextIn(display, Truncate(m.Title, w), Rect{0, 0, w, menuTitleHeight - 1}, AlignCenter, false)
+	FillRect(display, Rect{0, menuTitleHeight - 2, w, 1}, true)
+
+	// Keep the selection on screen
+	rows := visibleRows(display)
+	if m.selected < m.top {
+		m.top = m.selected
+	}
+	if m.selected >= m.top+rows {
+		m.top = m.selected - rows + 1
+	}
+
+	for i := 0; i < rows && m.top+i < len(m.Items); i++ {
+		idx := m.top + i
+		row := Rect{0, menuTitleHeight + int16(i)*menuRowHeight, menuBarWidth, menuRowHeight}
+		m.drawItem(display, &m.Items[idx], row, idx == m.selected)
+	}
+	m.drawScrollIndicator(display, rows)
+
+	display.Display()
+}
+
+// drawItem draws one row: the label on the left and whatever describes
+// the item on the right, inverted when selected
+func (m *Menu) drawItem(display display.Display, item *Item, row Rect, selected bool) {
+	right := ""
+	switch {
+	case item.Submenu != nil:
+		right = ">"
+	case item.Value != nil:
+		right = item.Value.String()
+		if selected && m.editing {
+			right = "<" + right + ">"
+		}
+	}
+
+	if selected {
+		FillRect(display, row, true)
+	}
+	inner := Rect{row.X + 2, row.Y, row.W - 4, row.H}
+	labelWidth := inner.W
+	if right != "" {
+		drawTextIn(display, right, inner, AlignRight, selected)
+		labelWidth -= TextWidth(right) + Font5x7.Advance
+	}
+	label := Truncate(item.Label, labelWidth)
+	drawTextIn(display, label, Rect{inner.X, inner.Y, labelWidth, inner.H}, AlignLeft, selected)
+}
+
+// drawScrollIndicator draws arrows at the right edge when there are items
+// above or below the screen, and a thumb showing where the screen is
+func (m *Menu) drawScrollIndicator(display display.Display, rows int) {
+	if len(m.Items) <= rows {
+		return
+	}
+	_, h := menuSize(display)
+	x := int16(menuBarWidth + 3) // centre of the column right of the bar
+	top := int16(menuTitleHeight)
+	bottom := h - 1
+
+	if m.top > 0 {
+		drawArrow(display, x, top, -1)
+	}
+	if m.top+rows < len(m.Items) {
+		drawArrow(display, x, bottom, 1)
+	}
+
+	// Thumb between the arrows
+	track := Rect{x, top + 4, 1, bottom - top - 7}
+	thumb := track.H * int16(rows) / int16(len(m.Items))
+	offset := (track.H - thumb) * int16(m.top) / int16(len(m.Items)-rows)
+	FillRect(display, Rect{x - 1, track.Y + offset, 3, max(thumb, 2)}, true)
+}
+
+// drawArrow draws a small triangle pointing up (dir < 0) or down, with
+// its tip at (x, y)
+func drawArrow(display display.Display, x, y, dir int16) {
+	for i := int16(0); i < 3; i++ {
+		FillRect(display, Rect{x - i, y - dir*i, 2*i + 1, 1}, true)
+	}
+}
+
+// Value is a setting a menu item can show and change
+type Value interface {
+	String() string
+	// Adjust steps the value up (+1) or down (-1)
+	Adjust(dir int)
+}
+
+// Slider is a number in a range, changed in steps
+type Slider struct {
+	Value          *int
+	Min, Max, Step int
+	Unit           string // shown after the number, e.g. "ms"
+	Zero           string // shown instead of 0, e.g. "Never"; empty shows 0
+}
+
+func (s *Slider) String() string {
+	if *s.Value == 0 && s.Zero != "" {
+		return s.Zero
+	}
+	return strconv.Itoa(*s.Value) + s.Unit
+}
+
+func (s *Slider) Adjust(dir int) {
+	step := max(s.Step, 1)
+	*s.Value = min(max(*s.Value+dir*step, s.Min), s.Max)
+}
+
+// Toggle is an on/off setting
+type Toggle struct {
+	Value *bool
+}
+
+func (t *Toggle) String() string {
+	if *t.Value {
+		return "ON"
+	}
+	return "OFF"
+}
+
+func (t *Toggle) Adjust(int) {
+	*t.Value = !*t.Value
+}
+
+// Choice is one of a list of options, stored as its index
+type Choice struct {
+	Value   *int
+	Options []string
+}
+
+func (c *Choice) String() string {
+	if *c.Value < 0 || *c.Value >= len(c.Options) {
+		return "?"
+	}
+	return c.Options[*c.Value]
+}
+
+func (c *Choice) Adjust(dir int) {
+	n := len(c.Options)
+	if n > 0 {
+		*c.Value = ((*c.Value+dir)%n + n) % n
+	}
+}
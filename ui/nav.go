@@ -0,0 +1,200 @@
+package ui
+
+import (
+	"time"
+
+	"gameoflife/input"
+)
+
+// Nav is a navigation event, whatever the controls that produced it
+type Nav int
+
+const (
+	NoNav     Nav = iota
+	NavNext       // move down / forward
+	NavPrev       // move up / back through the list
+	NavSelect     // choose the highlighted item, or pause in the game
+	NavBack       // leave the current screen
+	NavInfo       // show or hide extra information, such as the game HUD
+)
+
+// String names the event for log output
+func (n Nav) String() string {
+	switch n {
+	case NavNext:
+		return "next"
+	case NavPrev:
+		return "prev"
+	case NavSelect:
+		return "select"
+	case NavBack:
+		return "back"
+	case NavInfo:
+		return "info"
+	}
+	return "none"
+}
+
+// Navigator turns some set of controls into navigation events. Poll it
+// once per frame; it returns NoNav when nothing happened.
+type Navigator interface {
+	Nav() Nav
+}
+
+// ButtonNav navigates with a single button:
+//
+//	click          next
+//	hold           previous, repeating while held
+//	double click   select
+//	triple click   back
+//	click and hold info
+type ButtonNav struct {
+	button   input.Button
+	gestures *GestureDetector
+}
+
+// NewButtonNav creates single-button navigation
+func NewButtonNav(button input.Button) *ButtonNav {
+	return &ButtonNav{button: button, gestures: NewGestureDetector(DefaultGestures)}
+}
+
+func (n *ButtonNav) Nav() Nav {
+	switch n.gestures.Update(n.button.Pressed()) {
+	case Click:
+		return NavNext
+	case LongPress, Repeat:
+		return NavPrev
+	case DoubleClick:
+		return NavSelect
+	case TripleClick:
+		return NavBack
+	case ClickHold:
+		return NavInfo
+	}
+	return NoNav
+}
+
+// pushGestures are for buttons that only need click and hold: with no
+// double click to wait for, a click is reported as soon as it's released
+var pushGestures = GestureConfig{
+	Debounce:       DefaultGestures.Debounce,
+	LongPress:      DefaultGestures.LongPress,
+	RepeatInterval: DefaultGestures.RepeatInterval,
+}
+
+// ButtonsNav navigates with up, down and select buttons. Up and down
+// repeat while held; holding select goes back; pressing up and down
+// together is info.
+type ButtonsNav struct {
+	up, down, sel    input.Button
+	upG, downG, selG *GestureDetector
+	chord            bool // up and down were pressed together
+}
+
+// NewButtonsNav creates three-button navigation
+func NewButtonsNav(up, down, sel input.Button) *ButtonsNav {
+	// Up and down start repeating sooner than a long press on select
+	arrows := pushGestures
+	arrows.LongPress = 400 * time.Millisecond
+	return &ButtonsNav{
+		up: up, down: down, sel: sel,
+		upG:   NewGestureDetector(arrows),
+		downG: NewGestureDetector(arrows),
+		selG:  NewGestureDetector(pushGestures),
+	}
+}
+
+func (n *ButtonsNav) Nav() Nav {
+	// All three are polled every frame so their timings stay right
+	upPressed, downPressed := n.up.Pressed(), n.down.Pressed()
+	up := n.upG.Update(upPressed)
+	down := n.downG.Update(downPressed)
+	sel := n.selG.Update(n.sel.Pressed())
+
+	// A chord is reported once, and neither button does anything else
+	// until both are let go
+	if upPressed && downPressed && !n.chord {
+		n.chord = true
+		n.upG.Cancel()
+		n.downG.Cancel()
+		return NavInfo
+	}
+	if n.chord {
+		n.chord = upPressed || downPressed
+		up, down = NoGesture, NoGesture
+	}
+
+	switch {
+	case sel == LongPress:
+		return NavBack
+	case sel == Click:
+		return NavSelect
+	case up != NoGesture:
+		return NavPrev // click, long press or repeat
+	case down != NoGesture:
+		return NavNext
+	}
+	return NoNav
+}
+
+// EncoderNav navigates with a rotary encoder and its push switch:
+// turning moves through the list, a push selects, a long push goes back,
+// and turning while pushed in is info
+type EncoderNav struct {
+	encoder  input.Encoder
+	push     input.Button
+	gestures *GestureDetector
+	pending  int  // detents not yet reported
+	pushTurn bool // turned while pushed in; the push is used up
+}
+
+// NewEncoderNav creates rotary encoder navigation
+func NewEncoderNav(encoder input.Encoder, push input.Button) *EncoderNav {
+	return &EncoderNav{
+		encoder:  encoder,
+		push:     push,
+		gestures: NewGestureDetector(pushGestures),
+	}
+}
+
+func (n *EncoderNav) Nav() Nav {
+	// The push switch is polled every frame so its timing stays right
+	pushed := n.push.Pressed()
+	gesture := n.gestures.Update(pushed)
+	turns := n.encoder.Turns()
+
+	// Turning while pushed in is reported once per push, and the push
+	// is then neither a select nor a back
+	if pushed && turns != 0 {
+		n.gestures.Cancel()
+		n.pending = 0
+		if !n.pushTurn {
+			n.pushTurn = true
+			return NavInfo
+		}
+		return NoNav
+	}
+	if !pushed {
+		n.pushTurn = false
+	}
+
+	switch gesture {
+	case Click:
+		return NavSelect
+	case LongPress:
+		return NavBack
+	}
+
+	// A fast spin can turn several detents between frames; they are
+	// reported one per frame so none are lost
+	n.pending += turns
+	switch {
+	case n.pending > 0:
+		n.pending--
+		return NavNext
+	case n.pending < 0:
+		n.pending++
+		return NavPrev
+	}
+	return NoNav
+}
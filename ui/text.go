@@ -0,0 +1,140 @@
+package ui
+
+import (
+	"image/color"
+
+	"gameoflife/display"
+)
+
+// Rect is an area of the display
+type Rect struct {
+	X, Y, W, H int16
+}
+
+// contains reports whether the pixel (x, y) is inside r
+func (r Rect) contains(x, y int16) bool {
+	return x >= r.X && x < r.X+r.W && y >= r.Y && y < r.Y+r.H
+}
+
+// Align says where text sits horizontally
+type Align int
+
+const (
+	AlignLeft Align = iota
+	AlignCenter
+	AlignRight
+)
+
+// DrawText draws text in the 5x7 font with its top-left corner at (x, y)
+func DrawText(display display.Display, text string, x, y int16) {
+	w, h := display.Size()
+	drawString(display, text, x, y, Rect{0, 0, w, h}, false)
+}
+
+// TextWidth returns how many pixels wide text is, without the gap after
+// the last character
+func TextWidth(text string) int16 {
+	n := int16(len([]rune(text)))
+	if n == 0 {
+		return 0
+	}
+	return n*Font5x7.Advance - (Font5x7.Advance - Font5x7.Width)
+}
+
+// Truncate shortens text to fit in width pixels, ending it with ".." when
+// something had to be cut
+func Truncate(text string, width int16) string {
+	if TextWidth(text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for n := len(runes) - 1; n > 0; n-- {
+		if s := string(runes[:n]) + ".."; TextWidth(s) <= width {
+			return s
+		}
+	}
+	return ""
+}
+
+// DrawTextAligned draws text on one line at y, with x as its left edge,
+// centre or right edge depending on align
+func DrawTextAligned(display display.Display, text string, x, y int16, align Align) {
+	switch align {
+	case AlignCenter:
+		x -= TextWidth(text) / 2
+	case AlignRight:
+		x -= TextWidth(text)
+	}
+	DrawText(display, text, x, y)
+}
+
+// DrawTextCentered draws text centred across the whole display at y
+func DrawTextCentered(display display.Display, text string, y int16) {
+	w, _ := display.Size()
+	DrawTextAligned(display, text, w/2, y, AlignCenter)
+}
+
+// DrawTextIn draws text inside r, aligned horizontally and centred
+// vertically. Nothing is drawn outside r. Inverted fills r and draws the
+// text dark on it, as used for a selection bar.
+func DrawTextIn(display display.Display, text string, r Rect, align Align, inverted bool) {
+	if inverted {
+		FillRect(display, r, true)
+	}
+	drawTextIn(display, text, r, align, inverted)
+}
+
+// drawTextIn is DrawTextIn without filling the background, for putting
+// more than one piece of text on the same bar
+func drawTextIn(display display.Display, text string, r Rect, align Align, dark bool) {
+	x := r.X
+	switch align {
+	case AlignCenter:
+		x += (r.W - TextWidth(text)) / 2
+	case AlignRight:
+		x += r.W - TextWidth(text)
+	}
+	y := r.Y + (r.H-Font5x7.Height)/2
+	drawString(display, text, x, y, r, dark)
+}
+
+// FillRect turns every pixel in r on (or off)
+func FillRect(d display.Display, r Rect, on bool) {
+	c := display.Black
+	if on {
+		c = display.White
+	}
+	for y := r.Y; y < r.Y+r.H; y++ {
+		for x := r.X; x < r.X+r.W; x++ {
+			d.SetPixel(x, y, c)
+		}
+	}
+}
+
+// drawString draws text one glyph at a time, only inside clip
+func drawString(d display.Display, text string, x, y int16, clip Rect, dark bool) {
+	c := display.White
+	if dark {
+		c = display.Black
+	}
+	for _, char := range text {
+		if x >= clip.X+clip.W {
+			break // everything else is clipped too
+		}
+		if x+Font5x7.Width > clip.X {
+			drawGlyph(d, Font5x7.Glyph(char), x, y, clip, c)
+		}
+		x += Font5x7.Advance
+	}
+}
+
+func drawGlyph(d display.Display, glyph []byte, x, y int16, clip Rect, c color.RGBA) {
+	for col, bits := range glyph {
+		for row := int16(0); row < Font5x7.Height; row++ {
+			px, py := x+int16(col), y+row
+			if bits&(1<<uint(row)) != 0 && clip.contains(px, py) {
+				d.SetPixel(px, py, c)
+			}
+		}
+	}
+}
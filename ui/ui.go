@@ -0,0 +1,75 @@
+// Package ui has the text, menu and button handling shared by the OLED
+// screens
+package ui
+
+import (
+	"time"
+
+	"gameoflife/display"
+	"gameoflife/input"
+	"gameoflife/log"
+)
+
+var btnLog = log.New("BTN")
+
+// ShowMenu displays the pattern selection menu
+func ShowMenu(display display.Display, patterns []string, selected int) {
+	menu := NewListMenu("SELECT PATTERN", patterns)
+	menu.SetSelected(selected)
+	menu.Show(display)
+}
+
+// ClickDetector handles button click detection
+type ClickDetector struct {
+	lastButtonState bool
+	lastClickTime   time.Time
+	clickCount      int
+	clock           input.Clock
+}
+
+// NewClickDetector creates a new click detector
+func NewClickDetector() *ClickDetector {
+	return NewClickDetectorClock(input.SystemClock)
+}
+
+// NewClickDetectorClock creates a click detector that times clicks with
+// clock (an input.Script in tests)
+func NewClickDetectorClock(clock input.Clock) *ClickDetector {
+	return &ClickDetector{
+		lastButtonState: false,
+		lastClickTime:   clock.Now().Add(-1 * time.Second), // Start with old time
+		clickCount:      0,
+		clock:           clock,
+	}
+}
+
+// CheckClick returns: (singleClick bool, doubleClick bool)
+func (cd *ClickDetector) CheckClick(buttonPressed bool) (bool, bool) {
+	singleClick := false
+	doubleClick := false
+
+	// Detect button release (click)
+	if !buttonPressed && cd.lastButtonState {
+		// Button just released
+		timeSinceLastClick := cd.clock.Now().Sub(cd.lastClickTime)
+
+		btnLog.Debug("Click detected! Time since last:", timeSinceLastClick.Milliseconds(), "ms")
+
+		if timeSinceLastClick < 400*time.Millisecond {
+			// Double click detected
+			doubleClick = true
+			cd.clickCount++
+			btnLog.Debug("DOUBLE CLICK detected! Total clicks:", cd.clickCount)
+			cd.lastClickTime = cd.clock.Now().Add(-1 * time.Second) // Reset
+		} else {
+			// Single click
+			singleClick = true
+			cd.clickCount++
+			btnLog.Debug("Single click detected. Total clicks:", cd.clickCount)
+			cd.lastClickTime = cd.clock.Now()
+		}
+	}
+
+	cd.lastButtonState = buttonPressed
+	return singleClick, doubleClick
+}
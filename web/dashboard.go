@@ -0,0 +1,155 @@
+package web
+
+import (
+	_ "embed"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"gameoflife/console"
+	"gameoflife/display"
+	"gameoflife/input"
+)
+
+// The dashboard: the panel drawn live on a canvas, with buttons for the
+// console commands and the board's buttons
+//
+//go:embed dashboard.html
+var dashboard []byte
+
+// keepAlive is how often a quiet frame stream sends a comment, so proxies
+// and browsers don't drop it
+const keepAlive = 15 * time.Second
+
+// Button is one of the board's buttons, pressed from the dashboard
+type Button struct {
+	Name string
+	Key  *input.Key // one the game reads
+}
+
+// handleDashboard adds the dashboard's requests to mux:
+//
+//	GET  /                         the page
+//	GET  /info                     the game, buttons and whether a token is needed
+//	GET  /frames                   the panel's frames, as server-sent events
+//	POST /command                  a console command, the body's one line
+//	POST /buttons/{name}/{action}  press (down), release (up) or tap a button
+func (s *Server) handleDashboard(mux *http.ServeMux) {
+	mux.HandleFunc("GET /{$}", s.getDashboard)
+	mux.HandleFunc("GET /info", s.getInfo)
+	mux.HandleFunc("GET /frames", s.getFrames)
+	mux.HandleFunc("POST /command", s.authorized(s.postCommand))
+	mux.HandleFunc("POST /buttons/{name}/{action}", s.authorized(s.postButton))
+}
+
+func (s *Server) getDashboard(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(dashboard)
+}
+
+func (s *Server) getInfo(w http.ResponseWriter, r *http.Request) {
+	info := struct {
+		Game    string   `json:"game"`
+		Buttons []string `json:"buttons"`
+		Token   bool     `json:"token"`
+		Frames  bool     `json:"frames"`
+	}{Game: s.Game, Buttons: []string{}, Token: s.Token != "", Frames: s.Mirror != nil}
+	for _, b := range s.Buttons {
+		info.Buttons = append(info.Buttons, b.Name)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(info)
+}
+
+// getFrames streams the frames the Mirror passes on, one event each:
+//
+//	data: {"w":128,"h":64,"on":true,"pixels":"<base64, SSD1306 page layout>"}
+func (s *Server) getFrames(w http.ResponseWriter, r *http.Request) {
+	if s.Mirror == nil {
+		http.Error(w, "web: no display to mirror", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	rc := http.NewResponseController(w)
+
+	frames, stop := s.Mirror.Watch()
+	defer stop()
+	webLog.Debug("Dashboard watching from", r.RemoteAddr)
+	tick := time.NewTicker(keepAlive)
+	defer tick.Stop()
+	for {
+		select {
+		case f := <-frames:
+			event, _ := json.Marshal(frameEvent(f))
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", event); err != nil {
+				return
+			}
+		case <-tick.C:
+			if _, err := io.WriteString(w, ": still here\n\n"); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			webLog.Debug("Dashboard gone from", r.RemoteAddr)
+			return
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// frameEvent is a frame as getFrames sends it
+func frameEvent(f display.Frame) any {
+	return struct {
+		W      int16  `json:"w"`
+		H      int16  `json:"h"`
+		On     bool   `json:"on"`
+		Pixels string `json:"pixels"`
+	}{f.Width, f.Height, f.On, base64.StdEncoding.EncodeToString(f.Pixels)}
+}
+
+func (s *Server) postCommand(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, console.MaxLine+1))
+	line := strings.TrimSpace(string(body))
+	switch {
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case line == "" || strings.ContainsAny(line, "\r\n") || len(line) > console.MaxLine:
+		http.Error(w, "web: send one console command line", http.StatusBadRequest)
+	case strings.EqualFold(strings.Fields(line)[0], "load"):
+		// Its body would have to follow in the same request
+		http.Error(w, "web: send patterns to /pattern", http.StatusBadRequest)
+	default:
+		s.command(w, line)
+	}
+}
+
+func (s *Server) postButton(w http.ResponseWriter, r *http.Request) {
+	var key *input.Key
+	for _, b := range s.Buttons {
+		if strings.EqualFold(b.Name, r.PathValue("name")) {
+			key = b.Key
+		}
+	}
+	if key == nil {
+		http.Error(w, "web: no button called "+r.PathValue("name"), http.StatusNotFound)
+		return
+	}
+	switch r.PathValue("action") {
+	case "down":
+		key.SetHeld(true)
+	case "up":
+		key.SetHeld(false)
+	case "tap":
+		key.Tap()
+	default:
+		http.Error(w, "web: a button goes down, up or is tapped", http.StatusNotFound)
+		return
+	}
+	io.WriteString(w, "ok\n")
+}
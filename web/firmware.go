@@ -0,0 +1,110 @@
+package web
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// MaxFirmware is the largest image taken: the size of an app partition in
+// the ESP32's usual two-OTA-slot layout
+const MaxFirmware = 0x190000
+
+// imageMagic starts every ESP32 app image
+const imageMagic = 0xE9
+
+// Updater writes a firmware image where the board can start it from, as
+// ESP-IDF's OTA does into the spare app partition. The Server writes one
+// image at a time.
+type Updater interface {
+	// Begin starts writing an image of size bytes
+	Begin(size int64) error
+	Write(p []byte) (int, error)
+	// Abort throws away what has been written
+	Abort() error
+	// Commit makes the image the one to start, once it is complete and
+	// checked; on a board it restarts into it
+	Commit() error
+}
+
+// update writes an image from r with u, checking it is an ESP32 app image
+// of size bytes and, if sum isn't empty, that its SHA-256 is sum (in hex)
+func update(u Updater, r io.Reader, size int64, sum string) error {
+	if size <= 0 || size > MaxFirmware {
+		return fmt.Errorf("web: firmware image of %d bytes, must be 1 to %d", size, MaxFirmware)
+	}
+	if err := u.Begin(size); err != nil {
+		return err
+	}
+
+	hash := sha256.New()
+	buf := make([]byte, 4096)
+	var written int64
+	for written < size {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if written == 0 && buf[0] != imageMagic {
+				u.Abort()
+				return errors.New("web: not an ESP32 firmware image")
+			}
+			if written+int64(n) > size {
+				n = int(size - written)
+			}
+			hash.Write(buf[:n])
+			if _, err := u.Write(buf[:n]); err != nil {
+				u.Abort()
+				return err
+			}
+			written += int64(n)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			u.Abort()
+			return err
+		}
+	}
+	if written != size {
+		u.Abort()
+		return fmt.Errorf("web: firmware image cut short at %d of %d bytes", written, size)
+	}
+	if sum != "" && !strings.EqualFold(hex.EncodeToString(hash.Sum(nil)), sum) {
+		u.Abort()
+		return errors.New("web: firmware image doesn't match its SHA-256")
+	}
+	return u.Commit()
+}
+
+// FileUpdater is an Updater that keeps the image in a file, for the
+// simulator, which can't start it
+type FileUpdater struct {
+	Path string
+	f    *os.File // the image being written, under a temporary name
+}
+
+func (u *FileUpdater) Begin(size int64) error {
+	f, err := os.Create(u.Path + ".part")
+	u.f = f
+	return err
+}
+
+func (u *FileUpdater) Write(p []byte) (int, error) {
+	return u.f.Write(p)
+}
+
+func (u *FileUpdater) Abort() error {
+	u.f.Close()
+	return os.Remove(u.f.Name())
+}
+
+func (u *FileUpdater) Commit() error {
+	if err := u.f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(u.f.Name(), u.Path)
+}
@@ -0,0 +1,96 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Port is a console port in memory, for the firmware to take the web
+// server's commands on alongside the serial port's. Like a UART, reading
+// never waits and writing drops what there's no room for.
+type Port struct {
+	mu      sync.Mutex
+	in      []byte      // commands the firmware has yet to read
+	partial string      // the start of an answer line
+	answers chan string // the firmware's answer lines
+
+	command sync.Mutex // one command at a time
+}
+
+// NewPort creates a port
+func NewPort() *Port {
+	return &Port{answers: make(chan string, 256)}
+}
+
+// Read gives the firmware the commands that have arrived, or 0 bytes
+func (p *Port) Read(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := copy(b, p.in)
+	p.in = p.in[:copy(p.in, p.in[n:])]
+	return n, nil
+}
+
+// Write takes the firmware's answers
+func (p *Port) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	lines := strings.Split(p.partial+string(b), "\n")
+	p.partial = lines[len(lines)-1]
+	for _, line := range lines[:len(lines)-1] {
+		select {
+		case p.answers <- strings.TrimRight(line, "\r"):
+		default:
+		}
+	}
+	return len(b), nil
+}
+
+// ErrNoAnswer is returned when the firmware doesn't answer a command in
+// time: it is busy, or not taking commands from the port
+var ErrNoAnswer = errors.New("web: no answer")
+
+// Command sends a console command, with lines of body after it, and
+// returns its output. The firmware's "error: ..." answers are errors.
+func (p *Port) Command(cmd string, timeout time.Duration, body ...string) ([]string, error) {
+	p.command.Lock()
+	defer p.command.Unlock()
+
+	// Anything left from a command that timed out isn't this one's
+	for drained := false; !drained; {
+		select {
+		case <-p.answers:
+		default:
+			drained = true
+		}
+	}
+
+	msg := cmd + "\n"
+	for _, line := range body {
+		msg += line + "\n"
+	}
+	p.mu.Lock()
+	p.in = append(p.in, msg...)
+	p.mu.Unlock()
+
+	var out []string
+	deadline := time.After(timeout)
+	for {
+		select {
+		case line := <-p.answers:
+			switch {
+			case line == "ok":
+				return out, nil
+			case strings.HasPrefix(line, "error: "):
+				return out, errors.New(strings.TrimPrefix(line, "error: "))
+			default:
+				out = append(out, line)
+			}
+		case <-deadline:
+			return out, fmt.Errorf("%w to %q in %v", ErrNoAnswer, cmd, timeout)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+// Package web is an HTTP server for changing a display over the network:
+//
+//	GET  /pattern    the board as an RLE pattern
+//	POST /pattern    play the RLE pattern in the body
+//	GET  /settings   the settings, as name=value lines
+//	POST /settings   change and save settings: name=value lines or a form
+//	POST /firmware   install the firmware image in the body
+//
+// and a dashboard at / that draws the panel live, as a Mirror of the
+// game's display passes on its frames, with buttons for the commands and
+// the board's buttons (see handleDashboard).
+//
+// The server takes no part in the games: it turns requests into console
+// commands (see package console) on its Port, which the game reads as it
+// does the serial port, and answers with their output. A request the
+// running game has no command for, such as a pattern for Pong, fails.
+//
+// With a Token, the POSTs need it as "Authorization: Bearer <token>". A
+// firmware image is checked (an ESP32 app image of the size sent, with
+// the SHA-256 in the X-SHA256 header if there is one) before the Updater
+// commits it. The only Updater, FileUpdater, keeps it in a file.
+//
+// Only the simulator serves it for now (-http). The ESP32 firmwares don't:
+// TinyGo has no network driver for the ESP32's own Wi-Fi radio, nor access
+// to its OTA partitions, so there is no OTA Updater either.
+package web
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"gameoflife/console"
+	"gameoflife/display"
+	"gameoflife/log"
+	"gameoflife/rle"
+)
+
+// Timeout is how long a request waits for the game to answer
+const Timeout = 5 * time.Second
+
+var webLog = log.New("WEB")
+
+// Server answers the HTTP requests
+type Server struct {
+	Port    *Port   // pass to the game's Run with the serial port
+	Token   string  // needed to change anything, if set
+	Updater Updater // nil where firmware updates aren't supported
+
+	// For the dashboard
+	Game    string          // "life" or "pong", for the controls shown
+	Mirror  *display.Mirror // what the game draws on; nil: no frames
+	Buttons []Button        // the buttons it can press
+
+	updating sync.Mutex // held while a firmware image is written
+}
+
+// NewServer creates a server, without a token or firmware updates
+func NewServer() *Server {
+	return &Server{Port: NewPort()}
+}
+
+// Handler routes the requests to the server
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /pattern", s.getPattern)
+	mux.HandleFunc("POST /pattern", s.authorized(s.postPattern))
+	mux.HandleFunc("GET /settings", s.getSettings)
+	mux.HandleFunc("POST /settings", s.authorized(s.postSettings))
+	mux.HandleFunc("POST /firmware", s.authorized(s.postFirmware))
+	s.handleDashboard(mux)
+	return mux
+}
+
+// authorized wraps a handler that changes things, checking the token
+func (s *Server) authorized(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		given := []byte(r.Header.Get("Authorization"))
+		if s.Token != "" && subtle.ConstantTimeCompare(given, []byte("Bearer "+s.Token)) != 1 {
+			webLog.Warn("Refused", r.Method, r.URL.Path, "from", r.RemoteAddr)
+			http.Error(w, "web: wrong or missing token", http.StatusUnauthorized)
+			return
+		}
+		h(w, r)
+	}
+}
+
+func (s *Server) getPattern(w http.ResponseWriter, r *http.Request) {
+	s.command(w, "grid")
+}
+
+func (s *Server) postPattern(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, console.MaxBody+1))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(body) > console.MaxBody {
+		http.Error(w, "web: pattern longer than the console takes", http.StatusRequestEntityTooLarge)
+		return
+	}
+	// Read here first, so a bad pattern is reported without bothering the
+	// game, and sent re-encoded, in the short lines the console takes
+	p, err := rle.Decode(bytes.NewReader(body))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var b strings.Builder
+	if err := rle.EncodeRule(&b, p, p.Name, p.Rule); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	webLog.Info("Pattern from", r.RemoteAddr)
+	s.command(w, "load", lines...)
+}
+
+func (s *Server) getSettings(w http.ResponseWriter, r *http.Request) {
+	s.command(w, "settings")
+}
+
+func (s *Server) postSettings(w http.ResponseWriter, r *http.Request) {
+	var assignments []string
+	if r.Header.Get("Content-Type") == "application/x-www-form-urlencoded" {
+		body, err := io.ReadAll(io.LimitReader(r.Body, console.MaxLine))
+		form, parseErr := url.ParseQuery(string(body))
+		if err != nil || parseErr != nil {
+			http.Error(w, "web: unreadable form", http.StatusBadRequest)
+			return
+		}
+		for name, values := range form {
+			for _, v := range values {
+				assignments = append(assignments, name+"="+v)
+			}
+		}
+	} else {
+		body, err := io.ReadAll(io.LimitReader(r.Body, console.MaxLine))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		assignments = strings.Fields(string(body))
+	}
+	cmd := "set " + strings.Join(assignments, " ")
+	if len(assignments) == 0 || len(cmd) > console.MaxLine {
+		http.Error(w, "web: send name=value settings, up to a console line of them", http.StatusBadRequest)
+		return
+	}
+	webLog.Info("Settings from", r.RemoteAddr+":", strings.Join(assignments, " "))
+	s.command(w, cmd)
+}
+
+func (s *Server) postFirmware(w http.ResponseWriter, r *http.Request) {
+	if s.Updater == nil {
+		http.Error(w, "web: firmware updates aren't supported here", http.StatusNotImplemented)
+		return
+	}
+	if r.ContentLength < 0 {
+		http.Error(w, "web: send the image with its Content-Length", http.StatusLengthRequired)
+		return
+	}
+	// Requests are served at the same time, but an Updater writes one
+	// image at a time
+	if !s.updating.TryLock() {
+		http.Error(w, "web: another firmware image is being installed", http.StatusConflict)
+		return
+	}
+	defer s.updating.Unlock()
+	webLog.Info("Firmware image of", r.ContentLength, "bytes from", r.RemoteAddr)
+	if err := update(s.Updater, r.Body, r.ContentLength, r.Header.Get("X-SHA256")); err != nil {
+		webLog.Warn("Firmware update failed:", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	webLog.Info("Firmware image installed")
+	io.WriteString(w, "ok\n")
+}
+
+// command sends a console command to the game and writes its output
+// ("ok" if there is none), or its error
+func (s *Server) command(w http.ResponseWriter, cmd string, body ...string) {
+	out, err := s.Port.Command(cmd, Timeout, body...)
+	switch {
+	case errors.Is(err, ErrNoAnswer):
+		http.Error(w, err.Error(), http.StatusGatewayTimeout)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if len(out) == 0 {
+			out = []string{"ok"}
+		}
+		io.WriteString(w, strings.Join(out, "\n")+"\n")
+	}
+}
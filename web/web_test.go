@@ -0,0 +1,351 @@
+package web
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gameoflife/console"
+	"gameoflife/settings"
+)
+
+// testGame answers the server's commands on its Port, as the Game of
+// Life does, keeping the patterns loaded and the settings
+type testGame struct {
+	loaded chan string
+	config settings.Settings
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+func (g *testGame) run(port *Port) {
+	defer close(g.done)
+	con := console.New([]console.Command{
+		{Name: "grid"},
+		{Name: "load", Body: true},
+		{Name: "settings"},
+		{Name: "set"},
+	}, port)
+	for {
+		select {
+		case <-g.stop:
+			return
+		case <-time.After(time.Millisecond):
+		}
+		for req, ok := con.Next(); ok; req, ok = con.Next() {
+			var err error
+			switch req.Name {
+			case "grid":
+				con.Print("x = 3, y = 1, rule = B3/S23")
+				con.Print("3o!")
+			case "load":
+				g.loaded <- req.Body
+			case "settings":
+				for _, a := range g.config.Assignments() {
+					con.Print(a)
+				}
+			case "set":
+				err = g.config.Assign(req.Args)
+			}
+			con.Done(err)
+		}
+	}
+}
+
+// newTestServer serves s, with a game answering on its port
+func newTestServer(t *testing.T, s *Server) (*httptest.Server, *testGame) {
+	t.Helper()
+	g := &testGame{loaded: make(chan string, 8), config: settings.Defaults, stop: make(chan struct{}), done: make(chan struct{})}
+	go g.run(s.Port)
+	hs := httptest.NewServer(s.Handler())
+	t.Cleanup(func() {
+		hs.Close()
+		close(g.stop)
+		<-g.done
+	})
+	return hs, g
+}
+
+// do sends a request and returns the status and body of the answer
+func do(t *testing.T, method, url, token, contentType, body string) (int, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	b, _ := io.ReadAll(res.Body)
+	return res.StatusCode, string(b)
+}
+
+const glider = "#N Glider\nx = 3, y = 3\nbo$2bo$3o!\n"
+
+func TestToken(t *testing.T) {
+	s := NewServer()
+	s.Token = "secret"
+	hs, g := newTestServer(t, s)
+
+	tests := []struct {
+		method, path, token string
+		want                int
+	}{
+		{"POST", "/pattern", "", http.StatusUnauthorized},
+		{"POST", "/pattern", "wrong", http.StatusUnauthorized},
+		{"POST", "/settings", "", http.StatusUnauthorized},
+		{"POST", "/firmware", "wrong", http.StatusUnauthorized},
+		{"POST", "/command", "", http.StatusUnauthorized},
+		{"GET", "/pattern", "", http.StatusOK},
+		{"GET", "/settings", "", http.StatusOK},
+		{"POST", "/pattern", "secret", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path+" "+tt.token, func(t *testing.T) {
+			if status, body := do(t, tt.method, hs.URL+tt.path, tt.token, "", glider); status != tt.want {
+				t.Errorf("status %d (%s), want %d", status, strings.TrimSpace(body), tt.want)
+			}
+		})
+	}
+	// Only the one with the token got to the game
+	if len(g.loaded) != 1 {
+		t.Errorf("%d patterns loaded, want 1", len(g.loaded))
+	}
+}
+
+func TestTokenBeforePattern(t *testing.T) {
+	s := NewServer()
+	s.Token = "secret"
+	hs, g := newTestServer(t, s)
+
+	// A pattern that would be refused is refused for the token first
+	for _, body := range []string{"x = 2000000000, y = 2000000000\no!\n", strings.Repeat("b", console.MaxBody+1)} {
+		if status, _ := do(t, "POST", hs.URL+"/pattern", "", "", body); status != http.StatusUnauthorized {
+			t.Errorf("status %d, want %d", status, http.StatusUnauthorized)
+		}
+	}
+	if len(g.loaded) != 0 {
+		t.Errorf("%d patterns loaded without the token", len(g.loaded))
+	}
+}
+
+func TestPostPattern(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want int
+		load string // what the game is sent, if anything
+	}{
+		{"valid", glider, http.StatusOK, "#N Glider\nx = 3, y = 3, rule = B3/S23\nbo$2bo$3o!\n"},
+		{"malformed", "x = 3, y = three\n3o!\n", http.StatusBadRequest, ""},
+		{"too large a size", "x = 2000000000, y = 2000000000\no!\n", http.StatusBadRequest, ""},
+		{"oversized", "x = 3, y = 3\n" + strings.Repeat("#C padding\n", console.MaxBody/10) + "3o!\n", http.StatusRequestEntityTooLarge, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hs, g := newTestServer(t, NewServer())
+			if status, body := do(t, "POST", hs.URL+"/pattern", "", "", tt.body); status != tt.want {
+				t.Errorf("status %d (%s), want %d", status, strings.TrimSpace(body), tt.want)
+			}
+			select {
+			case body := <-g.loaded:
+				if body != tt.load {
+					t.Errorf("loaded %q, want %q", body, tt.load)
+				}
+			default:
+				if tt.load != "" {
+					t.Error("nothing loaded")
+				}
+			}
+		})
+	}
+}
+
+func TestGetPattern(t *testing.T) {
+	hs, _ := newTestServer(t, NewServer())
+	status, body := do(t, "GET", hs.URL+"/pattern", "", "", "")
+	if want := "x = 3, y = 1, rule = B3/S23\n3o!\n"; status != http.StatusOK || body != want {
+		t.Errorf("got %d %q, want %q", status, body, want)
+	}
+}
+
+func TestSettings(t *testing.T) {
+	hs, g := newTestServer(t, NewServer())
+
+	status, body := do(t, "GET", hs.URL+"/settings", "", "", "")
+	if want := strings.Join(settings.Defaults.Assignments(), "\n") + "\n"; status != http.StatusOK || body != want {
+		t.Errorf("GET: got %d %q, want %q", status, body, want)
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        int
+	}{
+		{"lines", "text/plain", "speed=50\nwrap=off\n", http.StatusOK},
+		{"form", "application/x-www-form-urlencoded", url.Values{"density": {"40"}}.Encode(), http.StatusOK},
+		{"out of range", "text/plain", "speed=1", http.StatusBadRequest},
+		{"no such setting", "text/plain", "colour=red", http.StatusBadRequest},
+		{"empty", "text/plain", "", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status, body := do(t, "POST", hs.URL+"/settings", "", tt.contentType, tt.body); status != tt.want {
+				t.Errorf("status %d (%s), want %d", status, strings.TrimSpace(body), tt.want)
+			}
+		})
+	}
+
+	status, body = do(t, "GET", hs.URL+"/settings", "", "", "")
+	for _, want := range []string{"speed=50", "wrap=off", "density=40"} {
+		if status != http.StatusOK || !strings.Contains(body, want+"\n") {
+			t.Errorf("after POST: got %d %q, want %s", status, body, want)
+		}
+	}
+	if g.config.Speed != 50 {
+		t.Errorf("game's speed %d, want 50", g.config.Speed)
+	}
+}
+
+func TestNoAnswer(t *testing.T) {
+	if testing.Short() {
+		t.Skip("waits for the timeout")
+	}
+	// A port nothing reads, as when the game doesn't take the server's
+	// commands
+	hs := httptest.NewServer(NewServer().Handler())
+	defer hs.Close()
+	if status, _ := do(t, "GET", hs.URL+"/settings", "", "", ""); status != http.StatusGatewayTimeout {
+		t.Errorf("status %d, want %d", status, http.StatusGatewayTimeout)
+	}
+}
+
+func TestFirmware(t *testing.T) {
+	image := append([]byte{imageMagic}, bytes.Repeat([]byte{0x5A}, 10000)...)
+	sum := sha256.Sum256(image)
+
+	t.Run("no updater", func(t *testing.T) {
+		hs, _ := newTestServer(t, NewServer())
+		if status, _ := do(t, "POST", hs.URL+"/firmware", "", "", string(image)); status != http.StatusNotImplemented {
+			t.Errorf("status %d, want %d", status, http.StatusNotImplemented)
+		}
+	})
+
+	tests := []struct {
+		name  string
+		image []byte
+		sum   string
+		want  int
+	}{
+		{"valid", image, hex.EncodeToString(sum[:]), http.StatusOK},
+		{"without a sum", image, "", http.StatusOK},
+		{"wrong sum", image, strings.Repeat("0", 64), http.StatusBadRequest},
+		{"not an image", append([]byte{0x7F}, image[1:]...), "", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "fw.bin")
+			s := NewServer()
+			s.Updater = &FileUpdater{Path: path}
+			hs, _ := newTestServer(t, s)
+
+			req, _ := http.NewRequest("POST", hs.URL+"/firmware", bytes.NewReader(tt.image))
+			if tt.sum != "" {
+				req.Header.Set("X-SHA256", tt.sum)
+			}
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res.Body.Close()
+			if res.StatusCode != tt.want {
+				t.Errorf("status %d, want %d", res.StatusCode, tt.want)
+			}
+
+			got, err := os.ReadFile(path)
+			if tt.want == http.StatusOK && (err != nil || !bytes.Equal(got, tt.image)) {
+				t.Errorf("image not installed: %v", err)
+			}
+			if tt.want != http.StatusOK && err == nil {
+				t.Error("a refused image was installed")
+			}
+			if _, err := os.Stat(path + ".part"); err == nil {
+				t.Error("the partly written image was left behind")
+			}
+		})
+	}
+}
+
+// slowUpdater holds an image's first write until it is released
+type slowUpdater struct {
+	FileUpdater
+	writing chan struct{} // closed when the first write starts
+	release chan struct{}
+	once    sync.Once
+}
+
+func (u *slowUpdater) Write(p []byte) (int, error) {
+	u.once.Do(func() {
+		close(u.writing)
+		<-u.release
+	})
+	return u.FileUpdater.Write(p)
+}
+
+func TestFirmwareOneAtATime(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fw.bin")
+	u := &slowUpdater{FileUpdater: FileUpdater{Path: path}, writing: make(chan struct{}), release: make(chan struct{})}
+	s := NewServer()
+	s.Updater = u
+	hs, _ := newTestServer(t, s)
+	image := append([]byte{imageMagic}, bytes.Repeat([]byte{0x5A}, 10000)...)
+
+	first := make(chan int)
+	go func() {
+		res, err := http.Post(hs.URL+"/firmware", "application/octet-stream", bytes.NewReader(image))
+		if err != nil {
+			first <- 0
+			return
+		}
+		res.Body.Close()
+		first <- res.StatusCode
+	}()
+	<-u.writing
+
+	// A second image while the first is being written is turned away,
+	// leaving the first to finish
+	if status, _ := do(t, "POST", hs.URL+"/firmware", "", "", string(image)); status != http.StatusConflict {
+		t.Errorf("second upload: status %d, want %d", status, http.StatusConflict)
+	}
+	close(u.release)
+	if status := <-first; status != http.StatusOK {
+		t.Errorf("first upload: status %d, want %d", status, http.StatusOK)
+	}
+	if got, err := os.ReadFile(path); err != nil || !bytes.Equal(got, image) {
+		t.Errorf("image not installed: %v", err)
+	}
+
+	// And once it is, the next can go
+	if status, _ := do(t, "POST", hs.URL+"/firmware", "", "", string(image)); status != http.StatusOK {
+		t.Errorf("third upload: status %d, want %d", status, http.StatusOK)
+	}
+}